@@ -2,48 +2,224 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"io"
 	"log"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
+	"github.com/joakim-ribier/pong/internal/audio"
 	"github.com/joakim-ribier/pong/internal/game"
+	"github.com/joakim-ribier/pong/internal/game/lan"
 	"github.com/joakim-ribier/pong/internal/game/local"
+	"github.com/joakim-ribier/pong/internal/game/menu"
 	"github.com/joakim-ribier/pong/internal/game/online"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/metrics"
+	"github.com/joakim-ribier/pong/internal/network/discovery"
+	"github.com/joakim-ribier/pong/internal/network/netsim"
+	"github.com/joakim-ribier/pong/internal/network/rollback"
 	"github.com/joakim-ribier/pong/pkg"
 	"github.com/joakim-ribier/pong/pkg/resources"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "discover" {
+		if err := discover(os.Args[2:]); err != nil {
+			exit(err)
+		}
+		return
+	}
+
 	debug := flag.Bool("debug", false, "enable the 2-D engine [debug] mode")
-	server := flag.String("server", "", "start a server [--server 0.0.0:3000] to host the game")
-	client := flag.String("client", "", "start a client [--client 0.0.0:3000] to connect to the server")
+	server := flag.String("server", "", "start a server [--server :3000, 0.0.0.0:3000, [::]:3000] to host the game")
+	client := flag.String("client", "", "start a client [--client localhost:3000, 192.168.1.10:3000, [::1]:3000] to connect to the server")
+	secret := flag.String("secret", "", "secure the room with a shared secret [--secret s3cr3t] (server and client)")
+	session := flag.String("session", "", "join back the match handed over to the client with the token of its seat [--session <token>] (client)")
+	joinLAN := flag.Bool("lan", false, "list the servers discovered on the LAN to join one of them")
+	local2P := flag.Bool("local", false, "start a local game for two players without the main menu")
 	verbose := flag.Bool("verbose", false, "enable the [verbose] mode to display logs")
+	logFile := flag.String("log-file", "", "write the logs [--log-file pong.log] to a file")
+	logFormat := flag.String("log-format", "text", "format of the logs [text|json]")
+	logLevel := flag.String("log-level", "", "minimum level of the logs [debug|info|warn|error] (default info, debug in verbose mode)")
+	logFilter := flag.String("log-filter", "", "minimum level per subsystem [--log-filter hub=warn,udp.server=debug]")
+	rollbackNetcode := flag.Bool("rollback", false, "play the hosted matches with the rollback netcode (server)")
+	inputDelay := flag.Int("input-delay", 2, fmt.Sprintf("delay of the inputs [0..%d] in frames with the rollback netcode (server)", rollback.MaxInputDelay))
+	noAudio := flag.Bool("no-audio", false, "disable the audio (e.g. headless runs without a sound device)")
+	settings := pkg.DefaultSettings
+	flag.Float64Var(&settings.Volume, "volume", settings.Volume, "volume of the sound effects [0..1] (change it from the pause menu)")
+	flag.BoolVar(&settings.Mute, "mute", settings.Mute, "mute the sound effects")
+	flag.Float64Var(&settings.UIScale, "ui-scale", settings.UIScale, fmt.Sprintf("scale of the texts %v", pkg.UIScales))
+	flag.StringVar(&settings.Palette, "palette", settings.Palette, fmt.Sprintf("colour-blind safe palette of the players and the ball %v", pkg.PaletteNames()))
+	flag.BoolVar(&settings.HighContrastTrail, "trail", settings.HighContrastTrail, "draw a high-contrast trail behind the ball")
+	flag.BoolVar(&settings.SlowBall, "slow-ball", settings.SlowBall, "slow the ball down in the local games")
+	flag.BoolVar(&settings.AudioCues, "audio-cues", settings.AudioCues, "play the position of the ball (panned by X, pitched by Y)")
+	noEffects := flag.Bool("no-effects", false, "disable the visual effects (ball trail, hit sparks, screen shake and score flash)")
+	fullscreen := flag.Bool("fullscreen", false, "start the game in fullscreen mode (toggle it with [F11])")
+	themeName := flag.String("theme", pkg.DefaultTheme, fmt.Sprintf("theme of the game %v or the path of a JSON theme file", pkg.Themes()))
+	metricsAddr := flag.String("metrics", "", "expose the metrics [--metrics 127.0.0.1:9100] on an HTTP endpoint")
+
+	conditions := netsim.Conditions{}
+	flag.DurationVar(&conditions.Latency, "netsim-latency", 0, "simulate a network latency [--netsim-latency 80ms]")
+	flag.DurationVar(&conditions.Jitter, "netsim-jitter", 0, "simulate a network jitter [--netsim-jitter 20ms]")
+	flag.Float64Var(&conditions.Loss, "netsim-loss", 0, "simulate a packet loss probability [--netsim-loss 0.05]")
+	flag.Float64Var(&conditions.Duplicate, "netsim-dup", 0, "simulate a packet duplication probability [--netsim-dup 0.01]")
+	flag.Float64Var(&conditions.Reorder, "netsim-reorder", 0, "simulate a packet reordering probability [--netsim-reorder 0.02]")
+	flag.Uint64Var(&conditions.Seed, "netsim-seed", 0, "seed of the network simulator (0 for a random one)")
 
 	flag.Parse()
-	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
-	if !*verbose {
-		log.SetOutput(io.Discard)
+	if err := setupLogging(*verbose, *logFile, *logFormat, *logLevel, *logFilter); err != nil {
+		exit(err)
+	}
+	if err := conditions.Validate(); err != nil {
+		exit(err)
+	}
+	if *server != "" && *client != "" {
+		exit(fmt.Errorf("--server and --client options can not be used together"))
+	}
+	if *joinLAN && (*server != "" || *client != "") {
+		exit(fmt.Errorf("--lan option can not be used with --server or --client"))
+	}
+	if *local2P && (*joinLAN || *server != "" || *client != "") {
+		exit(fmt.Errorf("--local option can not be used with --lan, --server or --client"))
+	}
+	if *inputDelay < 0 || *inputDelay > rollback.MaxInputDelay {
+		exit(fmt.Errorf("--input-delay option must be between 0 and %d frames", rollback.MaxInputDelay))
+	}
+	theme, err := pkg.LoadTheme(*themeName)
+	if err != nil {
+		exit(err)
+	}
+	pkg.UseTheme(theme)
+	settings.Effects = !*noEffects
+	if err := settings.Validate(); err != nil {
+		exit(err)
 	}
+	pkg.UseSettings(settings)
+	if !*noAudio {
+		sounds, err := audio.New()
+		if err != nil {
+			exit(err)
+		}
+		pkg.ListenGames(sounds.Subscribe)
+	}
+
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetFullscreen(*fullscreen)
 
-	pGame := genericsutil.When[*onlineMode, game.PGame](
-		parseOnlineModeParam(*server, *client), func(p *onlineMode) bool { return p != nil },
-		func(om *onlineMode) game.PGame {
-			return online.NewPGame(*debug, om.gameMode(), om.addr, resources.Version)
-		},
-		func() game.PGame { return local.NewPGame(*debug, resources.Version) })
+	if *metricsAddr != "" {
+		go func() {
+			if err := metrics.ListenAndServe(*metricsAddr); err != nil {
+				slog.Error("fail to expose metrics", "addr", *metricsAddr, "err", err)
+			}
+		}()
+	}
 
-	ebiten.SetWindowTitle(pGame.Title())
-	ebiten.SetWindowSize(
-		pGame.Drawer().Game.Screen.Width,
-		pGame.Drawer().Game.Screen.Height,
-	)
+	manager := menu.NewManager(menu.Options{
+		Debug:      *debug,
+		Version:    resources.Version,
+		Secret:     *secret,
+		Conditions: conditions,
+		Rollback:   *rollbackNetcode,
+		InputDelay: *inputDelay,
+	})
+
+	// the options of the command line start a game mode over the main menu
+	if *joinLAN {
+		manager.Push(lan.NewBrowser(*debug, resources.Version, *secret, conditions))
+	} else if om := parseOnlineModeParam(*server, *client); om != nil {
+		onlinePGame, err := online.NewPGame(*debug, om.gameMode(), om.addr, resources.Version, *secret, *session, conditions)
+		if err != nil {
+			exit(err)
+		}
+		if *rollbackNetcode && om.server {
+			onlinePGame.GameDrawer.WithRollback(*inputDelay)
+		}
+		manager.Push(game.FromPGame(onlinePGame))
+	} else if *local2P {
+		manager.Push(game.FromPGame(local.NewPGame(*debug, resources.Version)))
+	}
 
-	if err := ebiten.RunGame(pGame.Drawer()); err != nil {
+	ebiten.SetWindowSize(manager.Current().Size())
+
+	if err := ebiten.RunGame(manager); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// discover prints the servers discovered on the LAN
+func discover(args []string) error {
+	flags := flag.NewFlagSet("discover", flag.ExitOnError)
+	timeout := flags.Duration("timeout", 2*time.Second, "time to wait for the servers to answer")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	servers, err := discovery.Discover(discovery.DefaultPort, *timeout)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		fmt.Println("no server found on the LAN")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tADDR\tVERSION\tRULES\tSLOTS\tSECURED\tPING")
+	for _, server := range servers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%t\t%dms\n",
+			server.Name, server.Addr, server.Version, server.Rules.String(), server.FreeSlots, server.Secured, server.Ping.Milliseconds())
+	}
+	return w.Flush()
+}
+
+// exit prints the {err} startup error and exits the application
+func exit(err error) {
+	slog.Error("fail to start the application", "err", err)
+	fmt.Fprintf(os.Stderr, "pong: %v\n", err)
+	os.Exit(1)
+}
+
+// setupLogging configures the logs pipeline from the command line options
+func setupLogging(verbose bool, file, format, level, filter string) error {
+	opts := logging.Options{Format: format, Level: slog.LevelInfo}
+	if verbose {
+		opts.Output = os.Stderr
+		opts.Level = slog.LevelDebug
+	}
+
+	if file != "" {
+		f, err := logging.OpenFile(file)
+		if err != nil {
+			return err
+		}
+		opts.Output = genericsutil.When[io.Writer, io.Writer](
+			opts.Output, func(w io.Writer) bool { return w != nil },
+			func(w io.Writer) io.Writer { return io.MultiWriter(w, f) },
+			func() io.Writer { return f })
+	}
+
+	if level != "" {
+		l, err := logging.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		opts.Level = l
+	}
+
+	filters, err := logging.ParseFilters(filter)
+	if err != nil {
+		return err
+	}
+	opts.Filters = filters
+
+	return logging.Setup(opts)
+}
+
 type onlineMode struct {
 	addr   string
 	server bool
@@ -0,0 +1,196 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/pkg"
+	"github.com/joakim-ribier/pong/pkg/resources"
+)
+
+// SampleRate is the sample rate of the audio context
+const SampleRate = 44100
+
+const (
+	// baseSpeed is the X speed of the ball at the start of a set (the paddle hit is not pitched)
+	baseSpeed = 5
+	// maxPitch is the pitch of the paddle hit when the ball goes twice faster than at the start
+	maxPitch = 2
+	// pitchSteps rounds the pitch (1/20) to keep a few pitched sounds in memory
+	pitchSteps = 20
+	// minCuePitch and maxCuePitch are the pitches of the audio cue at the bottom and at the top of the table
+	minCuePitch = 0.75
+	maxCuePitch = 1.5
+	// minInterval ignores a sound played again in the interval (e.g. the frames simulated again by the rollback netcode)
+	minInterval = 50 * time.Millisecond
+)
+
+// Sound is the name of an embedded sound effect
+type Sound string
+
+const (
+	Beep  Sound = "beep"
+	Cue   Sound = "cue"
+	Go    Sound = "go"
+	Hit   Sound = "hit"
+	Point Sound = "point"
+	Wall  Sound = "wall"
+	Win   Sound = "win"
+)
+
+// Sounds are the sound effects embedded in the game
+var Sounds = []Sound{Beep, Cue, Go, Hit, Point, Wall, Win}
+
+// Audio plays the sound effects of the game events
+type Audio struct {
+	context *audio.Context
+	// sounds are the decoded PCM (16-bit stereo) by sound and by pitch
+	sounds  map[Sound][]byte
+	pitched map[pitchedSound][]byte
+
+	mu       sync.Mutex
+	playedAt map[Sound]time.Time
+
+	logger *slog.Logger
+}
+
+type pitchedSound struct {
+	sound Sound
+	pitch float64
+}
+
+// New builds the audio context and it decodes the embedded sound effects,
+// the volume of the player is read when a sound is played (see pkg.CurrentSettings)
+func New() (*Audio, error) {
+	sounds := make(map[Sound][]byte, len(Sounds))
+	for _, sound := range Sounds {
+		data, err := resources.Sounds.ReadFile("sounds/" + string(sound) + ".wav")
+		if err != nil {
+			return nil, err
+		}
+		stream, err := wav.DecodeWithSampleRate(SampleRate, bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sound '%s': %w", sound, err)
+		}
+		if sounds[sound], err = io.ReadAll(stream); err != nil {
+			return nil, fmt.Errorf("invalid sound '%s': %w", sound, err)
+		}
+	}
+
+	return &Audio{
+		context:  audio.NewContext(SampleRate),
+		sounds:   sounds,
+		pitched:  make(map[pitchedSound][]byte),
+		playedAt: make(map[Sound]time.Time),
+		logger:   logging.For(logging.Audio),
+	}, nil
+}
+
+// Subscribe plays the sound effects of the game {events}
+func (a *Audio) Subscribe(events *pkg.Events) {
+	pkg.Subscribe(events, func(e pkg.PaddleHit) { a.Play(Hit, Pitch(e.Speed)) })
+	pkg.Subscribe(events, func(pkg.WallBounce) { a.Play(Wall, 1) })
+	pkg.Subscribe(events, func(pkg.PointScored) { a.Play(Point, 1) })
+	pkg.Subscribe(events, func(e pkg.Countdown) {
+		if e.Remaining > 0 {
+			a.Play(Beep, 1)
+		} else {
+			a.Play(Go, 1)
+		}
+	})
+	pkg.Subscribe(events, func(pkg.MatchWon) { a.Play(Win, 1) })
+	pkg.Subscribe(events, func(e pkg.BallMoved) { a.PlayPanned(Cue, CuePitch(e.Y), 2*e.X-1) })
+}
+
+// Play plays the {sound} at the {pitch} (1 is the original pitch)
+func (a *Audio) Play(sound Sound, pitch float64) {
+	a.PlayPanned(sound, pitch, 0)
+}
+
+// PlayPanned plays the {sound} at the {pitch} panned to the left (-1) or to the right (1) by {pan}
+func (a *Audio) PlayPanned(sound Sound, pitch, pan float64) {
+	settings := pkg.CurrentSettings()
+	if settings.Mute || settings.Volume == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	now := time.Now()
+	if now.Sub(a.playedAt[sound]) < minInterval {
+		a.mu.Unlock()
+		return
+	}
+	a.playedAt[sound] = now
+	pcm := a.pcm(sound, pitch)
+	a.mu.Unlock()
+	if pan != 0 {
+		pcm = Pan(pcm, pan)
+	}
+
+	player := a.context.NewPlayerFromBytes(pcm)
+	player.SetVolume(settings.Volume)
+	player.Play()
+	a.logger.Debug("play sound", "sound", sound, "pitch", pitch, "pan", pan)
+}
+
+// pcm returns the PCM of the {sound} at the {pitch} (computed once)
+func (a *Audio) pcm(sound Sound, pitch float64) []byte {
+	if pitch == 1 {
+		return a.sounds[sound]
+	}
+	key := pitchedSound{sound: sound, pitch: pitch}
+	if pcm, ok := a.pitched[key]; ok {
+		return pcm
+	}
+	pcm := Resample(a.sounds[sound], pitch)
+	a.pitched[key] = pcm
+	return pcm
+}
+
+// Pitch returns the pitch of the paddle hit from the X {speed} of the ball
+func Pitch(speed float32) float64 {
+	pitch := math.Abs(float64(speed)) / baseSpeed
+	pitch = math.Max(1, math.Min(maxPitch, pitch))
+	return math.Round(pitch*pitchSteps) / pitchSteps
+}
+
+// CuePitch returns the pitch of the audio cue from the {y} position [0..1] of the ball (higher at the top)
+func CuePitch(y float64) float64 {
+	pitch := maxCuePitch - math.Max(0, math.Min(1, y))*(maxCuePitch-minCuePitch)
+	return math.Round(pitch*pitchSteps) / pitchSteps
+}
+
+// Pan returns a copy of the {pcm} (16-bit stereo) attenuated on the right (-1) or on the left (1) channel by {pan}
+func Pan(pcm []byte, pan float64) []byte {
+	gains := [2]float64{math.Min(1, 1-pan), math.Min(1, 1+pan)}
+	panned := make([]byte, len(pcm))
+	for i := 0; i+1 < len(pcm); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[i:])))
+		binary.LittleEndian.PutUint16(panned[i:], uint16(int16(sample*gains[i/2%2])))
+	}
+	return panned
+}
+
+// Resample plays the {pcm} (16-bit stereo) faster (higher) or slower (lower) by the {pitch} factor
+func Resample(pcm []byte, pitch float64) []byte {
+	const frameSize = 4
+	frames := len(pcm) / frameSize
+	resampled := make([]byte, 0, int(float64(frames)/pitch)*frameSize)
+	for i := 0; ; i++ {
+		src := int(float64(i) * pitch)
+		if src >= frames {
+			break
+		}
+		resampled = append(resampled, pcm[src*frameSize:(src+1)*frameSize]...)
+	}
+	return resampled
+}
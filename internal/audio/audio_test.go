@@ -0,0 +1,67 @@
+package audio
+
+import "testing"
+
+func TestPitch(t *testing.T) {
+	tests := []struct {
+		speed    float32
+		expected float64
+	}{
+		{5, 1},
+		{-5, 1},
+		{2, 1},
+		{7.5, 1.5},
+		{-7.6, 1.5},
+		{12, 2},
+	}
+	for _, test := range tests {
+		if pitch := Pitch(test.speed); pitch != test.expected {
+			t.Errorf("speed %0.2f: got pitch %0.2f, expected %0.2f", test.speed, pitch, test.expected)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	pcm := []byte{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3}
+
+	if resampled := Resample(pcm, 2); string(resampled) != string([]byte{0, 0, 0, 0, 2, 2, 2, 2}) {
+		t.Errorf("pitch 2: got %v", resampled)
+	}
+	if resampled := Resample(pcm, 0.5); len(resampled) != 2*len(pcm) {
+		t.Errorf("pitch 0.5: got %d bytes, expected %d", len(resampled), 2*len(pcm))
+	}
+	if resampled := Resample(pcm, 1); string(resampled) != string(pcm) {
+		t.Errorf("pitch 1: got %v", resampled)
+	}
+}
+
+func TestCuePitch(t *testing.T) {
+	tests := []struct {
+		y, expected float64
+	}{
+		{0, 1.5},
+		{0.5, 1.15},
+		{1, 0.75},
+		{1.2, 0.75},
+	}
+	for _, test := range tests {
+		if pitch := CuePitch(test.y); pitch != test.expected {
+			t.Errorf("y %0.2f: got pitch %0.2f, expected %0.2f", test.y, pitch, test.expected)
+		}
+	}
+}
+
+func TestPan(t *testing.T) {
+	// a frame with the 1000 sample on the left channel and the -1000 sample on the right one
+	pcm := []byte{0xe8, 0x03, 0x18, 0xfc}
+
+	if panned := Pan(pcm, 0); string(panned) != string(pcm) {
+		t.Errorf("center: got %v", panned)
+	}
+	if panned := Pan(pcm, -1); string(panned) != string([]byte{0xe8, 0x03, 0, 0}) {
+		t.Errorf("left: got %v", panned)
+	}
+	if panned := Pan(pcm, 0.5); string(panned) != string([]byte{0xf4, 0x01, 0x18, 0xfc}) {
+		t.Errorf("half right: got %v", panned)
+	}
+}
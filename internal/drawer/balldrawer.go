@@ -1,16 +1,18 @@
 package drawer
 
 import (
+	"image/color"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
 	"github.com/joakim-ribier/pong/pkg"
 )
 
-const IMPRESSIONS_MAX = 120
 const SPEED_RATIO = 1.05
 
 type BallDrawer struct {
 	ball     *pkg.Ball
+	color    color.Color
 	playerL  pkg.Player
 	playerR  pkg.Player
 	debug    bool
@@ -20,6 +22,7 @@ type BallDrawer struct {
 func NewBallDrawer(game pkg.Game) *BallDrawer {
 	return &BallDrawer{
 		ball:     game.Ball,
+		color:    game.Screen.AvailableColors[pkg.ColorBall],
 		playerL:  *game.PlayerL,
 		playerR:  *game.PlayerR,
 		debug:    game.Debug,
@@ -28,12 +31,7 @@ func NewBallDrawer(game pkg.Game) *BallDrawer {
 }
 
 func (b *BallDrawer) Draw(screen *ebiten.Image) {
-	DrawImage(screen, b.ball.Image, b.ball.Position)
-
-	// display ball impressions for debug
-	for _, imp := range b.ball.Impressions {
-		DrawImage(screen, &imp.Image, imp.Position)
-	}
+	DrawImageWithColor(screen, b.ball.Image, b.ball.Position, b.color)
 }
 
 func (b *BallDrawer) Update(game *pkg.Game, screen pkg.Screen, demo bool) {
@@ -45,30 +43,26 @@ func (b *BallDrawer) Update(game *pkg.Game, screen pkg.Screen, demo bool) {
 	if b.ball.Y+float32(b.ball.Height) >= float32(screen.YTop)-borderMarginY {
 		b.ball.YSpeed = -b.ball.YSpeed
 		b.ball.Y = float32(screen.YTop) - float32(b.ball.Height) - borderMarginY
+		game.Bounce()
 	} else if b.ball.Y <= float32(screen.YBottom)+borderMarginY {
 		b.ball.YSpeed = -b.ball.YSpeed
 		b.ball.Y = float32(screen.YBottom) + borderMarginY
+		game.Bounce()
 	}
 
 	if b.playerL.Hit(*b.ball) {
 		b.ball.XSpeed = -b.ball.XSpeed * genericsutil.OrElse[float32](SPEED_RATIO, func(v float32) bool { return !demo }, func() float32 { return 1 })
 		b.ball.X = b.playerL.Paddle.X + float32(b.playerL.Paddle.Width)
-		game.Hit()
+		game.Hit(pkg.PlayerLeft)
 	} else if b.playerR.Hit(*b.ball) {
 		b.ball.XSpeed = -b.ball.XSpeed * genericsutil.OrElse[float32](SPEED_RATIO, func(v float32) bool { return !demo }, func() float32 { return 1 })
 		b.ball.X = b.playerR.Paddle.X - float32(b.playerR.Paddle.Width/2) - float32(b.ball.Width/2)
-		game.Hit()
+		game.Hit(pkg.PlayerRight)
 	}
 
 	game.SetXSpeed(b.ball.XSpeed)
-
-	if b.debug {
-		// stack the impressions for debug
-		b.ball.Impressions = append(b.ball.Impressions, pkg.Impression{Position: b.ball.Position, Image: *b.ball.Image})
-		if nb := len(b.ball.Impressions); nb > IMPRESSIONS_MAX {
-			b.ball.Impressions = b.ball.Impressions[nb-IMPRESSIONS_MAX : IMPRESSIONS_MAX]
-		}
-	} else {
-		b.ball.Impressions = nil
+	if !demo {
+		game.Moved()
 	}
+
 }
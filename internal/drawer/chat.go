@@ -0,0 +1,173 @@
+package drawer
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// quickChats are the messages sent by the quick-chat hotkeys
+var quickChats = []struct {
+	key  ebiten.Key
+	text string
+}{
+	{ebiten.KeyF1, "gg"},
+	{ebiten.KeyF2, "nice shot"},
+	{ebiten.KeyF3, "good luck"},
+	{ebiten.KeyF4, "well played"},
+}
+
+// chatRate is the rate limit of the chat messages sent by the local player
+var chatRate = rate{perSecond: 0.5, burst: 3}
+
+// chat represents the chat mode which captures the text typed by the player
+type chat struct {
+	open    bool
+	input   []rune
+	limiter *bucket
+}
+
+// newChat builds a new {chat} type
+func newChat() *chat {
+	return &chat{limiter: newBucket(chatRate, time.Now())}
+}
+
+// updateChat handles the chat mode toggled by [enter] and the quick-chat hotkeys,
+// it returns true while the chat mode captures the keyboard
+func (g *GameDrawer) updateChat() bool {
+	if !g.chat.open {
+		for _, quickChat := range quickChats {
+			if inpututil.IsKeyJustPressed(quickChat.key) {
+				g.sendChat(quickChat.text)
+			}
+		}
+		// [enter] selects the items of the pause menu
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && !g.menuOpen() {
+			g.chat.open = true
+			g.chat.input = g.chat.input[:0]
+			// release the paddles which do not listen to the keyboard anymore
+			g.Game.PlayerL.Paddle.CurrentPressed = -1
+			g.Game.PlayerR.Paddle.CurrentPressed = -1
+		}
+		return g.chat.open
+	}
+
+	g.chat.input = ebiten.AppendInputChars(g.chat.input)
+	if len(g.chat.input) > network.ChatTextMaxLen {
+		g.chat.input = g.chat.input[:network.ChatTextMaxLen]
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.chat.input) > 0:
+		g.chat.input = g.chat.input[:len(g.chat.input)-1]
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.chat.open = false
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		g.chat.open = false
+		if text := string(g.chat.input); text != "" {
+			g.sendChat(text)
+		}
+	}
+
+	return true
+}
+
+// sendChat sends the {text} chat message to the remote side and it displays it in the [CHANNEL]
+func (g *GameDrawer) sendChat(text string) {
+	if !g.chat.limiter.take(chatRate, time.Now()) {
+		g.addMessageWithLevel("Slow down...", warning)
+		return
+	}
+
+	player := g.localPlayer()
+	message := network.ChatMessage{
+		Name:  player.Name,
+		Color: colorToHex(g.chatColor(player)),
+		Text:  text,
+	}.Sanitize()
+
+	g.send(network.NewMessage(network.Chat.String(), message))
+	g.showChat(message)
+}
+
+// showChat displays the chat {message} in the [CHANNEL] with the colour of its sender
+func (g *GameDrawer) showChat(message network.ChatMessage) {
+	c, ok := hexToColor(message.Color)
+	if !ok {
+		c = g.Game.Screen.AvailableColors[pkg.ColorText]
+	}
+	for _, line := range wrapText(fmt.Sprintf("%s: %s", message.Name, message.Text), g.chatLineLen()) {
+		g.channel.Info(line, "color", c)
+	}
+}
+
+// chatLineLen returns the number of characters of a line which fit in the [CHANNEL] panel
+func (g *GameDrawer) chatLineLen() int {
+	return max(1, (g.Game.Screen.RemoteExtendZoneW-100)/g.Game.Screen.Font.TinyTextSize)
+}
+
+// wrapText splits the {text} in lines of {size} characters at most, on the spaces when possible
+func wrapText(text string, size int) []string {
+	var lines []string
+	runes := []rune(strings.TrimSpace(text))
+	for len(runes) > size {
+		cut := size
+		for i := size; i > 0; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+	return append(lines, string(runes))
+}
+
+// chatColor returns the colour of the {player} in the chat
+func (g *GameDrawer) chatColor(player *pkg.Player) color.Color {
+	if player.Side == pkg.PlayerLeft {
+		return g.Game.Screen.AvailableColors[pkg.ColorAccent2]
+	}
+	return g.Game.Screen.AvailableColors[pkg.ColorTitle]
+}
+
+// drawChat draws the chat input line (or the chat help) at the {y} position of the remote zone
+func (g *GameDrawer) drawChat(screen *ebiten.Image, y float32) {
+	x := float32(g.Game.Screen.XLeft-float32(g.Game.Screen.RemoteExtendZoneW)) + 5
+	font := g.Game.Screen.Font.TinyText
+
+	if !g.chat.open {
+		DrawText(screen, "[enter] chat - [F1-F4] quick chat", font, g.Game.Screen.AvailableColors[pkg.ColorText], pkg.Position{X: x, Y: y})
+		return
+	}
+
+	cursor := ""
+	if time.Now().UnixMilli()/500%2 == 0 {
+		cursor = "_"
+	}
+	// the end of a long input is displayed, the panel is narrower than the message
+	input := g.chat.input
+	if size := (g.Game.Screen.RemoteExtendZoneW-25)/g.Game.Screen.Font.TinyTextSize - 3; len(input) > size {
+		input = input[len(input)-max(size, 0):]
+	}
+	DrawText(screen, fmt.Sprintf("> %s%s", string(input), cursor), font, g.Game.Screen.AvailableColors[pkg.ColorText], pkg.Position{X: x, Y: y})
+}
+
+// colorToHex formats the {c} colour as #rrggbb
+func colorToHex(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// hexToColor parses the {s} #rrggbb colour
+func hexToColor(s string) (color.Color, bool) {
+	c, err := pkg.ParseColor(s)
+	return c, err == nil
+}
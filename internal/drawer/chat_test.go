@@ -0,0 +1,34 @@
+package drawer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHexColor(t *testing.T) {
+	c, ok := hexToColor("#78e2a0")
+	if !ok || colorToHex(c) != "#78e2a0" {
+		t.Fatalf("got %v %t", c, ok)
+	}
+	if _, ok := hexToColor("white"); ok {
+		t.Fatal("expected an invalid colour")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text     string
+		size     int
+		expected []string
+	}{
+		{"bob: gg", 10, []string{"bob: gg"}},
+		{"bob: well played", 10, []string{"bob: well", "played"}},
+		{"bob: aaaaaaaaaaaaaa", 10, []string{"bob:", "aaaaaaaaaa", "aaaa"}},
+		{"éééééééééééé", 5, []string{"ééééé", "ééééé", "éé"}},
+	}
+	for _, tt := range tests {
+		if lines := wrapText(tt.text, tt.size); !slices.Equal(lines, tt.expected) {
+			t.Errorf("wrapText(%q, %d) = %q, expected %q", tt.text, tt.size, lines, tt.expected)
+		}
+	}
+}
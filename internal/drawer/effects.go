@@ -0,0 +1,224 @@
+package drawer
+
+import (
+	"image/color"
+	"math"
+	"math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+const (
+	// maxParticles is the size of the pool of particles, the oldest one is reused when the pool is full
+	maxParticles = 128
+	// particleTicks is the life of a spark
+	particleTicks = 24
+	// sparksPerSpeed is the number of sparks of a paddle hit by unit of X speed of the ball
+	sparksPerSpeed = 1.5
+	// shakeSpeed is the X speed of the ball from which a return shakes the screen
+	shakeSpeed = 9
+	// shakeTicks and shakeAmplitude are the duration and the max offset (logical pixels) of a screen shake
+	shakeTicks     = 12
+	shakeAmplitude = 6
+	// flashTicks is the duration of the flash of the table when a point is scored
+	flashTicks = 20
+	// hitTicks ignores the same paddle hit published again on the next frames (the ball still touches the paddle)
+	hitTicks = 10
+	// effectsTrailLength is the number of positions of the ball drawn by the trail
+	effectsTrailLength = 12
+)
+
+// particle is a spark of the pool, it is alive until its {age} reaches its {ttl}
+type particle struct {
+	pkg.Position
+	vx, vy   float32
+	size     float32
+	age, ttl int
+	color    color.Color
+}
+
+func (p particle) alive() bool {
+	return p.age < p.ttl
+}
+
+// effects draws the visual effects of the game (ball trail, hit sparks, screen shake and score flash)
+// from its events, every effect is drawn with the same sprite and the particles reuse the slots of a pool
+type effects struct {
+	sprite  *ebiten.Image
+	options ebiten.DrawImageOptions
+
+	particles [maxParticles]particle
+	next      int
+
+	trail []pkg.Position
+
+	shake      int
+	flash      int
+	flashColor color.Color
+
+	lastHit struct {
+		side pkg.PlayerSide
+		tick int64
+	}
+}
+
+// newEffects builds a new {effects} type
+func newEffects() *effects {
+	e := &effects{trail: make([]pkg.Position, 0, effectsTrailLength+1)}
+	e.lastHit.tick = -hitTicks
+	return e
+}
+
+// enabled returns true if the player did not disable the effects in the settings
+func (e *effects) enabled() bool {
+	return pkg.CurrentSettings().Effects
+}
+
+// hit emits the sparks of a paddle hit of the {side} player at the {position} of the ball,
+// their number grows with the {speed} of the ball and a fast return shakes the screen
+func (e *effects) hit(side pkg.PlayerSide, position pkg.Position, speed float32, tick int64, c color.Color) {
+	if !e.enabled() || (side == e.lastHit.side && tick-e.lastHit.tick < hitTicks && tick >= e.lastHit.tick) {
+		return
+	}
+	e.lastHit.side, e.lastHit.tick = side, tick
+
+	speed = float32(math.Abs(float64(speed)))
+	direction := float32(1)
+	if side == pkg.PlayerRight {
+		direction = -1
+	}
+	for range int(speed * sparksPerSpeed) {
+		angle := (rand.Float64() - 0.5) * math.Pi * 0.8
+		velocity := float32(1+rand.Float64()) * speed / 3
+		e.emit(particle{
+			Position: position,
+			vx:       direction * velocity * float32(math.Cos(angle)),
+			vy:       velocity * float32(math.Sin(angle)),
+			size:     float32(2 + rand.IntN(3)),
+			ttl:      particleTicks/2 + rand.IntN(particleTicks/2),
+			color:    c,
+		})
+	}
+
+	if speed >= shakeSpeed {
+		e.shake = shakeTicks
+	}
+}
+
+// score flashes the table with the {c} colour of the player who scored
+func (e *effects) score(c color.Color) {
+	if !e.enabled() {
+		return
+	}
+	e.flash, e.flashColor = flashTicks, c
+}
+
+// emit puts the {p} particle in a free slot of the pool (or in the slot of the oldest one)
+func (e *effects) emit(p particle) {
+	slot := e.next
+	for i := range maxParticles {
+		if index := (e.next + i) % maxParticles; !e.particles[index].alive() {
+			slot = index
+			break
+		}
+	}
+	e.particles[slot] = p
+	e.next = (slot + 1) % maxParticles
+}
+
+// alive returns the number of particles alive in the pool
+func (e *effects) alive() int {
+	nb := 0
+	for _, p := range e.particles {
+		if p.alive() {
+			nb++
+		}
+	}
+	return nb
+}
+
+// update moves the particles, it fades the shake and the flash out and it stacks the {ball} position in the trail
+func (e *effects) update(ball pkg.Position, width int) {
+	for i := range e.particles {
+		if p := &e.particles[i]; p.alive() {
+			p.X += p.vx
+			p.Y += p.vy
+			p.vx *= 0.92
+			p.vy *= 0.92
+			p.age++
+		}
+	}
+	e.shake = max(0, e.shake-1)
+	e.flash = max(0, e.flash-1)
+
+	if nb := len(e.trail); nb > 0 {
+		if last := e.trail[nb-1]; last == ball {
+			return
+		} else if math.Abs(float64(last.X-ball.X)) > float64(width*4) {
+			// the ball was reset to the center of the table
+			e.trail = e.trail[:0]
+		}
+	}
+	e.trail = append(e.trail, ball)
+	if nb := len(e.trail); nb > effectsTrailLength {
+		e.trail = append(e.trail[:0], e.trail[nb-effectsTrailLength:]...)
+	}
+}
+
+// offset returns the offset of the screen shaken after a fast return
+func (e *effects) offset() (float64, float64) {
+	if e.shake == 0 || !e.enabled() {
+		return 0, 0
+	}
+	amplitude := shakeAmplitude * float64(e.shake) / shakeTicks
+	return (rand.Float64()*2 - 1) * amplitude, (rand.Float64()*2 - 1) * amplitude
+}
+
+// draw draws the flash of the {table}, the trail of the ball (drawn with the {ball} colour when {visible})
+// and the sparks on the {screen}, the high-contrast trail of the accessibility settings is drawn even without the effects
+func (e *effects) draw(screen *ebiten.Image, table pkg.Screen, width int, visible bool, ball, highContrast color.Color) {
+	if e.sprite == nil {
+		e.sprite = ebiten.NewImage(1, 1)
+		e.sprite.Fill(color.White)
+	}
+	settings := pkg.CurrentSettings()
+
+	if settings.Effects && e.flash > 0 {
+		e.drawSprite(screen, table.XLeft, table.YBottom, float32(table.GameZoneWidth()), float32(table.GameZoneHeight()),
+			e.flashColor, 0.3*float32(e.flash)/flashTicks)
+	}
+
+	if visible && len(e.trail) > 1 {
+		for i, position := range e.trail[:len(e.trail)-1] {
+			ratio := float32(i+1) / float32(len(e.trail))
+			size := max(2, float32(width)*ratio)
+			offset := (float32(width) - size) / 2
+			switch {
+			case settings.HighContrastTrail:
+				e.drawSprite(screen, position.X+offset, position.Y+offset, size, size, highContrast, 1)
+			case settings.Effects:
+				e.drawSprite(screen, position.X+offset, position.Y+offset, size, size, ball, ratio*0.5)
+			}
+		}
+	}
+
+	if settings.Effects {
+		for _, p := range e.particles {
+			if p.alive() {
+				e.drawSprite(screen, p.X, p.Y, p.size, p.size, p.color, 1-float32(p.age)/float32(p.ttl))
+			}
+		}
+	}
+}
+
+// drawSprite draws the sprite scaled to {w, h} at the {x, y} position tinted by the {c} colour and faded by {alpha}
+func (e *effects) drawSprite(screen *ebiten.Image, x, y, w, h float32, c color.Color, alpha float32) {
+	e.options.GeoM.Reset()
+	e.options.GeoM.Scale(float64(w), float64(h))
+	e.options.GeoM.Translate(float64(x), float64(y))
+	e.options.ColorScale.Reset()
+	e.options.ColorScale.ScaleWithColor(c)
+	e.options.ColorScale.ScaleAlpha(alpha)
+	screen.DrawImage(e.sprite, &e.options)
+}
@@ -0,0 +1,83 @@
+package drawer
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+func TestEffectsHit(t *testing.T) {
+	defer pkg.UseSettings(pkg.CurrentSettings())
+	pkg.UseSettings(pkg.DefaultSettings)
+
+	e := newEffects()
+	e.hit(pkg.PlayerLeft, pkg.Position{}, 5, 0, color.White)
+	slow := e.alive()
+	if slow == 0 || e.shake != 0 {
+		t.Fatalf("got %d sparks and a shake of %d ticks", slow, e.shake)
+	}
+
+	// the same hit published again on the next frames
+	e.hit(pkg.PlayerLeft, pkg.Position{}, 5, 2, color.White)
+	if e.alive() != slow {
+		t.Errorf("got %d sparks, expected %d", e.alive(), slow)
+	}
+
+	e.hit(pkg.PlayerRight, pkg.Position{}, -10, 3, color.White)
+	if fast := e.alive() - slow; fast <= slow || e.shake != shakeTicks {
+		t.Errorf("got %d sparks (%d) and a shake of %d ticks", fast, slow, e.shake)
+	}
+
+	for range particleTicks {
+		e.update(pkg.Position{}, 16)
+	}
+	if e.alive() != 0 || e.shake != 0 {
+		t.Errorf("got %d sparks and a shake of %d ticks after their life", e.alive(), e.shake)
+	}
+}
+
+func TestEffectsPool(t *testing.T) {
+	defer pkg.UseSettings(pkg.CurrentSettings())
+	pkg.UseSettings(pkg.DefaultSettings)
+
+	e := newEffects()
+	for tick := range int64(20) {
+		e.hit(pkg.PlayerLeft, pkg.Position{}, 12, tick*hitTicks, color.White)
+	}
+	if e.alive() != maxParticles {
+		t.Errorf("got %d sparks, expected the size of the pool", e.alive())
+	}
+}
+
+func TestEffectsDisabled(t *testing.T) {
+	defer pkg.UseSettings(pkg.CurrentSettings())
+	settings := pkg.DefaultSettings
+	settings.Effects = false
+	pkg.UseSettings(settings)
+
+	e := newEffects()
+	e.hit(pkg.PlayerLeft, pkg.Position{}, 12, 0, color.White)
+	e.score(color.White)
+	if x, y := e.offset(); e.alive() != 0 || e.flash != 0 || x != 0 || y != 0 {
+		t.Errorf("got %d sparks, a flash of %d ticks and a shake of %0.2f %0.2f", e.alive(), e.flash, x, y)
+	}
+}
+
+func TestEffectsTrail(t *testing.T) {
+	e := newEffects()
+	for x := range 20 {
+		// the ball does not move during a pause
+		e.update(pkg.Position{X: float32(x) * 5}, 16)
+		e.update(pkg.Position{X: float32(x) * 5}, 16)
+	}
+	if len(e.trail) != effectsTrailLength || e.trail[effectsTrailLength-1].X != 95 {
+		t.Fatalf("got %v", e.trail)
+	}
+
+	// the ball is reset to the center of the table
+	e.update(pkg.Position{X: 500}, 16)
+	if len(e.trail) != 1 {
+		t.Errorf("got %v", e.trail)
+	}
+}
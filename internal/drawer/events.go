@@ -0,0 +1,86 @@
+package drawer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/joakim-ribier/pong/internal/metrics"
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// subscribe subscribes the logs, the metrics, the network, the effects and the [CHANNEL] to the events of the game
+func (g *GameDrawer) subscribe(events *pkg.Events) {
+	events.Listen(func(e pkg.Event) {
+		if !g.logger.Enabled(context.Background(), slog.LevelDebug) {
+			return
+		}
+		g.logger.Debug("game event", "event", fmt.Sprintf("%T", e), "value", fmt.Sprintf("%+v", e))
+	})
+
+	// metrics
+	pkg.Subscribe(events, func(e pkg.SetStarted) {
+		if e.Number == 1 {
+			metrics.ActiveMatches.Set(1)
+		}
+	})
+	pkg.Subscribe(events, func(pkg.MatchWon) {
+		metrics.ActiveMatches.Set(0)
+		if size := len(g.Game.Win.Sets); size > 0 {
+			metrics.MatchDuration.Observe((g.Game.Win.Sets[size-1].EndTime - g.Game.Win.Sets[0].StartTime).Seconds())
+		}
+	})
+	pkg.Subscribe(events, func(e pkg.PeerLeft) {
+		metrics.ClientRTT.Delete(e.Addr)
+		metrics.PingFailures.Delete(e.Addr)
+	})
+
+	// network
+	pkg.Subscribe(events, func(e pkg.StateChanged) {
+		if g.sharesState(e.To) {
+			g.send(network.NewMessage(network.UpdateCurrentState.String(), e.To.String()))
+		}
+	})
+
+	// effects
+	pkg.Subscribe(events, func(e pkg.PaddleHit) {
+		ball := g.Game.Ball
+		center := pkg.Position{X: ball.X + float32(ball.Width)/2, Y: ball.Y + float32(ball.Height)/2}
+		g.effects.hit(e.Side, center, e.Speed, g.Game.Clock.Ticks(), g.Game.Player(e.Side).Options.Color)
+	})
+	pkg.Subscribe(events, func(e pkg.PointScored) { g.effects.score(g.Game.Player(e.Side).Options.Color) })
+
+	// [CHANNEL]
+	pkg.Subscribe(events, func(e pkg.PointScored) {
+		g.addMessageWithLevel(fmt.Sprintf("%s wins the point", g.Game.Player(e.Side).Name), logg)
+	})
+	pkg.Subscribe(events, func(e pkg.SetStarted) {
+		if g.Game.IsLocal() {
+			return
+		}
+		if e.Number == 1 {
+			g.addMessageWithLevel("Start a new game", logg)
+		}
+		g.addMessageWithLevel(fmt.Sprintf("Start new set (%d)", e.Number), logg)
+	})
+	pkg.Subscribe(events, func(pkg.MatchWon) {
+		g.addMessageWithLevel("End of the game", logg)
+		if player := g.Game.Winner(); player != nil {
+			g.addMessageWithLevel(fmt.Sprintf("%s wins! (%d/%d)", player.Name, player.Score, g.Game.Looser().Score), info)
+		}
+	})
+	pkg.Subscribe(events, func(e pkg.Paused) {
+		if e.Paused {
+			g.addMessageWithLevel("Pause game...", logg)
+		}
+	})
+	pkg.Subscribe(events, func(e pkg.PeerJoined) {
+		g.addMessageWithLevel("New subscriber...", logg)
+		g.addMessageWithLevel(fmt.Sprintf("%s connected", e.Addr), logg)
+	})
+	pkg.Subscribe(events, func(e pkg.PeerLeft) {
+		g.addMessageWithLevel("Lost connection...", warning)
+		g.addMessageWithLevel(fmt.Sprintf("%s disconnected", e.Addr), warning)
+	})
+}
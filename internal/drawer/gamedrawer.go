@@ -2,9 +2,15 @@ package drawer
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"image/color"
+	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -14,10 +20,15 @@ import (
 	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
 	"github.com/joakim-ribier/go-utils/pkg/mapsutil"
 	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/metrics"
 	"github.com/joakim-ribier/pong/internal/network"
 	"github.com/joakim-ribier/pong/pkg"
 )
 
+// ErrMainMenu is returned by the {Update} method when the player leaves the game for the main menu
+var ErrMainMenu = errors.New("back to the main menu")
+
 type GameDrawer struct {
 	Game *pkg.Game
 
@@ -26,11 +37,26 @@ type GameDrawer struct {
 
 	shutdown func()
 	send     func(msg network.Message)
+	promote  func(port int, done func(err error))
 	version  string
 
 	keys []ebiten.Key
 
 	remoteData *networkData
+	inbox      inbox
+	chat       *chat
+	lobby      *lobby
+	menu       *pauseMenu
+	netcode    *netcode
+	effects    *effects
+	letterbox  Letterbox
+
+	// announcement is the state of the game read by the LAN announcer
+	announcement atomic.Pointer[Announcement]
+
+	channel   *slog.Logger
+	logger    *slog.Logger
+	antiCheat *slog.Logger
 }
 
 func NewDrawerGame(
@@ -39,17 +65,44 @@ func NewDrawerGame(
 	shutdown func(),
 	version string) *GameDrawer {
 
-	return &GameDrawer{
+	remoteData := newNetworkData()
+
+	g := &GameDrawer{
 		Game:          game,
 		shutdown:      shutdown,
 		send:          send,
 		version:       version,
 		BallDrawer:    *NewBallDrawer(*game),
-		PlayersDrawer: *NewPlayerDrawer(*game),
-		remoteData:    newNetworkData()}
+		PlayersDrawer: *NewPlayerDrawer(game),
+		remoteData:    remoteData,
+		chat:          newChat(),
+		netcode:       &netcode{},
+		menu:          &pauseMenu{},
+		effects:       newEffects(),
+		lobby:         newLobby(game.Screen.AvailableColors, genericsutil.When[bool, string](game.IsRemoteClient(), func(b bool) bool { return b }, func(b bool) string { return pkg.ColorPlayerR }, func() string { return pkg.ColorPlayerL })),
+		channel:       logging.For(logging.Channel, channelHandler{data: remoteData}),
+		logger:        logging.For(logging.GameState),
+		antiCheat:     logging.For(logging.AntiCheat)}
+	g.subscribe(game.Events)
+	g.hookRollback()
+	g.hookMenu()
+	g.publishAnnouncement()
+	return g
+}
+
+// WithBot lets the computer play the paddle of the {bot} side
+func (g *GameDrawer) WithBot(bot *pkg.Bot) *GameDrawer {
+	g.PlayersDrawer.bot = bot
+	return g
 }
 
 func (g *GameDrawer) Draw(screen *ebiten.Image) {
+	g.letterbox.Shake(g.effects.offset())
+	g.letterbox.Draw(screen, g.Game.Screen, g.draw)
+}
+
+// draw draws the game on the {screen} in the logical coordinates
+func (g *GameDrawer) draw(screen *ebiten.Image) {
 	// draw the logo and the title
 	g.drawBackgroundZone(screen)
 
@@ -60,13 +113,18 @@ func (g *GameDrawer) Draw(screen *ebiten.Image) {
 	if g.Game.Debug {
 		var b bytes.Buffer
 		b.WriteString(fmt.Sprintf("Ticks per secondes: %0.2f", ebiten.ActualTPS()))
-		b.WriteString(fmt.Sprintf("\nScreen W.H: %d %d", screen.Bounds().Size().X, screen.Bounds().Size().Y))
-		b.WriteString("\nGame current state: " + g.Game.CurrentState.String())
+		windowW, windowH := g.letterbox.Size()
+		b.WriteString(fmt.Sprintf("\nScreen W.H: %d %d (window %d %d)", screen.Bounds().Size().X, screen.Bounds().Size().Y, windowW, windowH))
+		b.WriteString("\nGame current state: " + g.Game.CurrentState().String())
+		b.WriteString(fmt.Sprintf("\nSeed: %d", g.Game.Random.Seed()))
 		b.WriteString(fmt.Sprintf("\nBall X.Y: %0.2f %0.2f", g.Game.Ball.X, g.Game.Ball.Y))
 		b.WriteString(fmt.Sprintf("\nPlayer L X.Y: %0.2f %0.2f", g.Game.PlayerL.Paddle.X, g.Game.PlayerL.Paddle.Y))
 		b.WriteString(fmt.Sprintf("\nPlayer R X.Y: %0.2f %0.2f", g.Game.PlayerR.Paddle.X, g.Game.PlayerR.Paddle.Y))
+		if !g.Game.IsLocal() {
+			b.WriteString(g.rollbackDebug())
+		}
 		for nb, set := range g.Game.Win.Sets {
-			if !set.EndTime.IsZero() {
+			if set.Ended() {
 				b.WriteString(fmt.Sprintf("\nSet n°%d: Time=%s, Speed=%0.02f, PlayerL=%d, PlayerR=%d, Win=%s",
 					nb+1,
 					set.Duration(),
@@ -82,51 +140,48 @@ func (g *GameDrawer) Draw(screen *ebiten.Image) {
 	// draw the "how to play" and player's score
 	g.drawGameZoneText(screen)
 
+	// draw the lobby (names, colours and rules) before the game
+	if g.lobbyOpen() {
+		g.drawLobby(screen)
+	}
+
 	// draw the paddles
-	if g.Game.CurrentState != pkg.WinGame {
+	if g.Game.CurrentState() != pkg.WinGame {
 		g.PlayersDrawer.Draw(screen)
 	}
 
-	// draw the ball
-	if g.Game.CurrentState != pkg.ResumeGame && g.Game.CurrentState != pkg.WinGame {
+	// draw the effects (score flash, ball trail, sparks) and the ball
+	ballVisible := g.Game.CurrentState() != pkg.ResumeGame && g.Game.CurrentState() != pkg.WinGame
+	g.effects.draw(screen, g.Game.Screen, g.Game.Ball.Width, ballVisible,
+		g.Game.Screen.AvailableColors[pkg.ColorBall], g.Game.Screen.AvailableColors[pkg.ColorText])
+	if ballVisible {
 		g.BallDrawer.Draw(screen)
 	}
 
 	// draw the counter zone between each set 3..2..1
-	if g.Game.CurrentState == pkg.ResumeGame {
-		tps := ebiten.ActualTPS()
-
-		if int(float64(g.Game.ResumeGameState.Count)/tps) >= g.Game.ResumeGameState.Max {
-			g.Game.CurrentState = pkg.PlayGame
-		} else {
-			remainingTime := g.Game.ResumeGameState.Max - int(float64(g.Game.ResumeGameState.Count)/tps)
-			if remainingTime >= 0 {
-				displayRemainingTime := fmt.Sprintf("%d", remainingTime)
-				g.Game.ResumeGameState.Count++
-
-				DrawText(screen, displayRemainingTime, g.Game.Screen.Font.H2, g.Game.Screen.AvailableColors["white"],
-					pkg.Position{
-						X: float32(int(g.Game.Screen.XRight)-(len(displayRemainingTime)*g.Game.Screen.Font.H2Size)) - 50,
-						Y: float32(int(g.Game.Screen.YBottom)) + 25},
-				)
-			}
-		}
+	if remainingTime := g.Game.ResumeGameState.Remaining; g.Game.CurrentState() == pkg.ResumeGame && remainingTime > 0 {
+		displayRemainingTime := fmt.Sprintf("%d", remainingTime)
+		DrawText(screen, displayRemainingTime, g.Game.Screen.Font.H2, g.Game.Screen.AvailableColors[pkg.ColorText],
+			pkg.Position{
+				X: float32(int(g.Game.Screen.XRight)-(len(displayRemainingTime)*g.Game.Screen.Font.H2Size)) - 50,
+				Y: float32(int(g.Game.Screen.YBottom)) + 25},
+		)
 	}
 
 	// draw other info during a playing set...
-	if g.Game.CurrentState == pkg.PlayGame || g.Game.CurrentState == pkg.ResumeGame || g.Game.CurrentState == pkg.PauseGame {
+	if g.Game.CurrentState() == pkg.PlayGame || g.Game.CurrentState() == pkg.ResumeGame || g.Game.CurrentState() == pkg.PauseGame {
 		posX := float32(g.Game.Screen.XLeft + 50)
 		posY := float32(g.Game.Screen.YBottom + 30)
 		marginY := float32(g.Game.Screen.Font.SmallTextSize + 10)
 		marginX := float32(60)
 		font := g.Game.Screen.Font.SmallText
 		fontSize := g.Game.Screen.Font.SmallTextSize
-		color := g.Game.Screen.AvailableColors["white"]
+		color := g.Game.Screen.AvailableColors[pkg.ColorText]
 
 		currentSet := g.Game.CurrentSet()
 		lastSet := g.Game.LastEndedSet()
 
-		time := time.Unix(0, 0).UTC().Add(time.Since(currentSet.StartTime).Round(time.Second)).Format("04:05")
+		time := pkg.FormatDuration(g.Game.Clock.Since(currentSet.StartTime))
 		DrawText(screen, "Time:", font, color, pkg.Position{X: posX, Y: posY})
 		DrawText(screen, time, font, color, pkg.Position{X: posX + marginX, Y: posY})
 		posY += marginY
@@ -150,10 +205,15 @@ func (g *GameDrawer) Draw(screen *ebiten.Image) {
 	}
 
 	// draw the winner zone
-	if g.Game.CurrentState == pkg.WinGame {
+	if g.Game.CurrentState() == pkg.WinGame {
 		g.drawWinnerGameZone(screen)
 	}
 
+	// draw the pause menu over the table
+	if g.menuOpen() {
+		g.drawMenu(screen)
+	}
+
 	// draw the remote info game zone
 	if !g.Game.IsLocal() {
 		g.drawRemoteGameZone(screen)
@@ -161,19 +221,36 @@ func (g *GameDrawer) Draw(screen *ebiten.Image) {
 }
 
 func (g *GameDrawer) Update() error {
+	g.Game.Clock.Tick()
+	g.handleInbox()
 	g.keys = inpututil.AppendPressedKeys(g.keys[:0])
+	UpdateFullscreen()
 
-	// handle the shutdown by the user CTRL+C
+	// handle the shutdown by the user CTRL+C or from the pause menu
 	shutdown := len(slicesutil.FilterT[ebiten.Key](g.keys, func(k ebiten.Key) bool {
 		return k == ebiten.KeyControl || k == ebiten.KeyC
 	})) == 2
-	if shutdown {
+	if shutdown || g.menu.quit || g.menu.back {
+		g.migrate()
 		g.shutdown()
-		return fmt.Errorf("shutdown app '%s' now", g.Game.Title.Text)
+		if g.menu.back {
+			g.logger.Info("leave the game", "title", g.Game.Title.Text)
+			return ErrMainMenu
+		}
+		g.logger.Info("shutdown app", "title", g.Game.Title.Text)
+		return ebiten.Termination
 	}
 
+	// the chat mode, the lobby and the pause menu capture the keyboard
+	chatting := !g.Game.IsLocal() && g.updateChat()
+	inLobby := !chatting && g.updateLobby()
+	inMenu := !chatting && g.updateMenu()
+	g.PlayersDrawer.inputLocked = chatting || inLobby || inMenu
+	g.syncLobby(time.Now())
+	g.syncMenu(time.Now())
+
 	// draw the ping-pong table on the start screen as a logo
-	if g.Game.CurrentState == pkg.StartGame {
+	if g.Game.CurrentState() == pkg.StartGame {
 		screen := g.Game.Screen
 		screen.YBottom = float32(g.Game.Screen.GameZoneYCenter()) - float32(g.Game.PlayerL.Paddle.Height/2) - 15
 		screen.YTop = float32(g.Game.Screen.GameZoneYCenter()) + float32(g.Game.PlayerL.Paddle.Height/2) + 15
@@ -185,19 +262,27 @@ func (g *GameDrawer) Update() error {
 		g.BallDrawer.Update(g.Game, screen, true)
 	}
 
-	if g.Game.CurrentState == pkg.PlayGame {
+	g.updateCountdown()
+
+	// the rollback netcode simulates the ball and both paddles from the inputs of the players
+	rollbackNetcode := g.netcode.session != nil && g.Game.CurrentState() == pkg.PlayGame
+	if rollbackNetcode {
+		g.advanceRollback()
+	} else if g.Game.CurrentState() == pkg.PlayGame {
 		g.BallDrawer.Update(g.Game, g.Game.Screen, false)
 	}
 
-	if g.Game.CurrentState == pkg.PlayGame || g.Game.CurrentState == pkg.ResumeGame {
+	if !rollbackNetcode && (g.Game.CurrentState() == pkg.PlayGame || g.Game.CurrentState() == pkg.ResumeGame) {
 		g.updatePlayer(g.BallDrawer.playerL)
 		g.updatePlayer(g.BallDrawer.playerR)
 	}
+	g.effects.update(g.Game.Ball.Position, g.Game.Ball.Width)
+	g.publishAnnouncement()
 
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) && !g.Game.IsRemoteClient() {
-		switch g.Game.CurrentState {
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) && !g.Game.IsRemoteClient() && !chatting {
+		switch g.Game.CurrentState() {
 		case pkg.PlayGame:
-			g.updateCurrentState(pkg.PauseGame)
+			g.pauseBy(g.localPlayer())
 		case pkg.StartGame:
 			if g.Game.IsRemoteServer() && !g.remoteData.readyToPlay.ready {
 				g.addMessageWithLevel(fmt.Sprintf("%s is not ready", g.Game.PlayerR.Name), warning)
@@ -211,27 +296,47 @@ func (g *GameDrawer) Update() error {
 		}
 	}
 
-	if g.Game.IsRemoteClient() && g.Game.CurrentState == pkg.StartGame {
-		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+	// the client requests the host to pause or to resume the game
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) && g.Game.IsRemoteClient() && !chatting {
+		switch g.Game.CurrentState() {
+		case pkg.PlayGame:
+			g.requestPause(true)
+		case pkg.PauseGame:
+			g.requestPause(false)
+		}
+	}
+
+	if g.Game.IsRemoteClient() && g.Game.CurrentState() == pkg.StartGame {
+		if inpututil.IsKeyJustPressed(ebiten.KeySpace) && !chatting {
 			g.remoteData.readyToPlay.ready = !g.remoteData.readyToPlay.ready
-			g.remoteData.readyToPlay.nbTpsLaps = 0
+			g.remoteData.readyToPlay.since = g.Game.Clock.Now()
 
 			// send the cmd to the remote side
 			g.send(network.NewMessage(network.Ready.String(), g.remoteData.readyToPlay.ready))
 		}
-		if g.remoteData.readyToPlay.ready {
-			nbSeconds := computeSecondFromTPS(g.remoteData.readyToPlay.nbTpsLaps)
-			g.remoteData.readyToPlay.nbSeconds = nbSeconds
-			if nbSeconds > g.remoteData.readyToPlay.nbSecondsMax {
-				g.remoteData.readyToPlay.nbTpsLaps = -1
-			}
-			g.remoteData.readyToPlay.nbTpsLaps += 1
+		if ready := &g.remoteData.readyToPlay; ready.ready {
+			// the dots of the animation loop every {nbSecondsMax}+1 seconds
+			ready.nbSeconds = int(g.Game.Clock.Since(ready.since)/time.Second) % (ready.nbSecondsMax + 1)
 		}
 	}
 
 	return nil
 }
 
+// updateCountdown counts the seconds down before a set then it plays the set
+func (g *GameDrawer) updateCountdown() {
+	if g.Game.CurrentState() != pkg.ResumeGame {
+		return
+	}
+	remainingTime := g.Game.RemainingCountdown()
+	if remainingTime <= 0 {
+		g.updateCurrentState(pkg.PlayGame)
+		g.Game.Countdown(0)
+		return
+	}
+	g.Game.Countdown(remainingTime)
+}
+
 func (g *GameDrawer) updatePlayer(player pkg.Player) {
 	currentY := player.Paddle.Y
 	if state := g.PlayersDrawer.update(player, g.Game.Screen); state.PlayerLostBall() {
@@ -242,74 +347,202 @@ func (g *GameDrawer) updatePlayer(player pkg.Player) {
 	}
 }
 
+// updateCurrentState goes to the {state} through a transition of the state machine,
+// an illegal transition is ignored
 func (g *GameDrawer) updateCurrentState(state pkg.State) {
-	g.Game.CurrentState = state
+	from := g.Game.CurrentState()
+	if err := g.Game.Transition(state); err != nil {
+		g.logger.Warn("fail to update current state", "err", err)
+		return
+	}
+	g.logger.Info("update current state", "from", from.String(), "to", state.String())
 	switch state {
 	case pkg.PlayerLLostBall:
-		if g.Game.IsRemoteServer() {
-			g.send(network.NewMessage(network.UpdateCurrentState.String(), g.Game.CurrentState.String()))
-		}
-		g.addMessageWithLevel("Player R wins the point", logg)
 		g.playerWinSet(g.Game.PlayerR)
 	case pkg.PlayerRLostBall:
-		if g.Game.IsRemoteClient() {
-			g.send(network.NewMessage(network.UpdateCurrentState.String(), g.Game.CurrentState.String()))
-		}
-		g.addMessageWithLevel("Player L wins the point", logg)
 		g.playerWinSet(g.Game.PlayerL)
-	case pkg.PauseGame:
-		if g.Game.IsRemoteServer() {
-			g.send(network.NewMessage(network.UpdateCurrentState.String(), g.Game.CurrentState.String()))
-		}
-		g.addMessageWithLevel("Pause game...", logg)
-	case pkg.PlayGame:
-		if g.Game.IsRemoteServer() {
-			g.send(network.NewMessage(network.UpdateCurrentState.String(), g.Game.CurrentState.String()))
-		}
 	case pkg.ResumeGame:
+		if len(g.Game.Win.Sets) == 0 {
+			// the match starts from its seed on both sides
+			g.Game.NewMatch(g.Game.Random.Seed())
+		}
 		if g.Game.IsLocal() {
 			g.Game.StartNewSet()
 			return
 		}
-		if g.Game.IsRemoteServer() {
-			g.send(network.NewMessage(network.UpdateCurrentState.String(), g.Game.CurrentState.String()))
-		}
-		if size := len(g.Game.Win.Sets); size == 0 {
-			g.addMessageWithLevel("Start a new game", logg)
-		}
 		g.Game.StartNewSet()
-		g.addMessageWithLevel(fmt.Sprintf("Start new set (%d)", len(g.Game.Win.Sets)), logg)
+		g.startRollback()
+		g.remoteData.swap = swapRequests{}
+		for _, client := range g.remoteData.clients {
+			client.guard.resetPaddle()
+		}
 	case pkg.StartGame:
+		metrics.ActiveMatches.Set(0)
 		g.remoteData.readyToPlay.ready = false
-		if g.Game.IsRemoteServer() {
-			g.send(network.NewMessage(network.UpdateCurrentState.String(), g.Game.CurrentState.String()))
-		}
 		g.Game.ResetGame()
+		if !g.Game.IsRemoteClient() {
+			g.newMatch()
+		}
 	case pkg.WinGame:
 		g.remoteData.readyToPlay.ready = false
-		g.addMessageWithLevel("End of the game", logg)
-		if player := g.Game.Winner(); player != nil {
-			g.addMessageWithLevel(fmt.Sprintf("%s wins! (%d/%d)", player.Name, player.Score, g.Game.Looser().Score), info)
-		}
 	}
 }
 
+// sharesState returns true if the {state} entered on this side is sent to the remote side,
+// the host leads the match and each side tells when its player lost the ball
+func (g *GameDrawer) sharesState(state pkg.State) bool {
+	switch state {
+	case pkg.PlayerLLostBall:
+		return !g.Game.IsLocal() && g.Game.LocalSide() == pkg.PlayerLeft
+	case pkg.PlayerRLostBall:
+		return !g.Game.IsLocal() && g.Game.LocalSide() == pkg.PlayerRight
+	case pkg.PauseGame, pkg.PlayGame, pkg.ResumeGame, pkg.StartGame:
+		return g.Game.IsRemoteServer()
+	}
+	return false
+}
+
 func (g *GameDrawer) HandleNetworkMessage(message network.Message) {
+	// a disconnected client which talks again from the same address resumes its session
+	if client, ok := g.remoteData.clients[message.NetworkAddr]; ok && client.disconnected() {
+		if cmd := message.AsCMD(); cmd != network.Shutdown && cmd != network.Subscribe {
+			g.resumeSession(client, message.NetworkAddr)
+		}
+	}
+
+	// the server validates the inputs of its clients
+	if client, ok := g.remoteData.clients[message.NetworkAddr]; ok && g.Game.IsRemoteServer() && message.AsCMD() != network.Shutdown {
+		if !client.guard.allow(message.AsCMD(), time.Now()) {
+			g.violation(client, message, "rate limit exceeded")
+			return
+		}
+	}
+
 	switch message.AsCMD() {
+	case network.Chat:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			chatMessage, err := network.DecodeValue[network.ChatMessage](message)
+			if err != nil {
+				if g.Game.IsRemoteServer() {
+					g.violation(client, message, "invalid value")
+				}
+				return
+			}
+			g.showChat(chatMessage.Sanitize())
+		}
+	case network.Forfeit:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			value, _ := message.Data.Value.(string)
+			// a player only forfeits the match for itself
+			if side := pkg.ToPlayerSide(value); side != g.Game.LocalSide().Other() {
+				if g.Game.IsRemoteServer() {
+					g.violation(client, message, "invalid side")
+				}
+				return
+			}
+			g.applyForfeit(g.Game.LocalSide().Other())
+		}
+	case network.Input:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			frames, err := network.DecodeValue[network.InputFrames](message)
+			if (err != nil || !g.addRemoteInputs(frames)) && g.Game.IsRemoteServer() {
+				g.violation(client, message, "invalid inputs")
+			}
+		}
+	case network.Lobby:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			state, err := network.DecodeValue[network.LobbyState](message)
+			if err != nil {
+				if g.Game.IsRemoteServer() {
+					g.violation(client, message, "invalid value")
+				}
+				return
+			}
+			if g.Game.IsRemoteServer() && state.Rules != nil {
+				g.violation(client, message, "the rules belong to the host")
+				return
+			}
+			g.applyLobbyState(state)
+		}
+	case network.Menu:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			state, err := network.DecodeValue[network.MenuState](message)
+			if err != nil {
+				if g.Game.IsRemoteServer() {
+					g.violation(client, message, "invalid value")
+				}
+				return
+			}
+			state = state.Sanitize()
+			if g.Game.IsRemoteServer() && state.PausedBy != "" {
+				g.violation(client, message, "the pause belongs to the host")
+				return
+			}
+			g.menu.remote = state.Item
+			if state.PausedBy != "" {
+				g.menu.pausedBy = state.PausedBy
+			}
+		}
+	case network.Migrate:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok && g.Game.IsRemoteClient() {
+			token, ok := message.Data.Value.(string)
+			if !ok || token == "" {
+				// the seat of the former host could be taken by anyone without its token
+				g.logger.Warn("ignore the migration without session token", "addr", message.NetworkAddr)
+				return
+			}
+			g.takeOver(client, token)
+		}
 	case network.Notify:
 		if _, ok := g.remoteData.clients[message.NetworkAddr]; ok {
 			g.addMessageWithLevel(fmt.Sprintf("%s", message.Data.Value), info)
 		}
+	case network.Pause:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok && g.Game.IsRemoteServer() {
+			pause, ok := message.Data.Value.(bool)
+			if !ok {
+				g.violation(client, message, "invalid value")
+				return
+			}
+			// the host acknowledges the request with the new state of the game
+			if pause && g.Game.CurrentState() == pkg.PlayGame {
+				g.addMessageWithLevel(fmt.Sprintf("%s paused the game", g.remotePlayer().Name), info)
+				g.pauseBy(g.remotePlayer())
+			} else if !pause && g.Game.CurrentState() == pkg.PauseGame {
+				g.addMessageWithLevel(fmt.Sprintf("%s resumed the game", g.remotePlayer().Name), info)
+				g.updateCurrentState(pkg.PlayGame)
+			}
+		}
 	case network.Ping:
 		if _, ok := g.remoteData.clients[message.NetworkAddr]; ok {
 			g.send(network.NewMessage(network.Pong.String(), g.version).WithAddr(message.NetworkAddr))
 		}
 	case network.PingAll:
 		for _, client := range g.remoteData.clients {
+			if client.migrated {
+				continue
+			}
+			if client.disconnected() {
+				if time.Since(client.disconnectedAt) >= g.remoteData.sessionGraceWindow {
+					// delete the subscriber if it did not resume its session in time...
+					if g.Game.IsRemoteServer() {
+						g.send(network.NewSimpleMessage(network.Shutdown.String()).WithAddr(client.networkAddr))
+					}
+					g.HandleNetworkMessage(network.NewSimpleMessage(network.Shutdown.String()).WithAddr(client.networkAddr))
+					return
+				}
+				if g.Game.IsRemoteClient() {
+					// try to resume the session
+					g.send(network.NewMessage(network.Subscribe.String(), network.NewHello(g.remoteData.session)))
+				}
+				continue
+			}
 			if client.nbPingAttempts >= client.nbPingMaxAttempts {
-				// delete the subscriber if it not responding...
-				g.HandleNetworkMessage(network.NewSimpleMessage(network.Shutdown.String()).WithAddr(client.networkAddr))
-				return
+				g.disconnect(client)
+				continue
+			}
+			if client.nbPingAttempts > 0 {
+				metrics.PingFailures.With(client.networkAddr).Inc()
 			}
 			client.lastPing = time.Now()
 			client.nbPingAttempts += 1
@@ -318,11 +551,18 @@ func (g *GameDrawer) HandleNetworkMessage(message network.Message) {
 		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
 			client.lastPong = time.Now()
 			client.nbPingAttempts = 0
-			client.version = message.Data.Value.(string)
+			client.version, _ = message.Data.Value.(string)
+			metrics.ClientRTT.With(client.networkAddr).Set(client.ping().Seconds())
+			metrics.RTT.Observe(client.ping().Seconds())
 		}
 	case network.Ready:
-		if _, ok := g.remoteData.clients[message.NetworkAddr]; ok {
-			g.remoteData.readyToPlay.ready = message.Data.Value.(bool)
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			ready, ok := message.Data.Value.(bool)
+			if !ok {
+				g.violation(client, message, "invalid value")
+				return
+			}
+			g.remoteData.readyToPlay.ready = ready
 			if g.Game.IsRemoteServer() {
 				if g.remoteData.readyToPlay.ready {
 					g.addMessageWithLevel(fmt.Sprintf("%s ready to play", message.NetworkAddr), info)
@@ -332,41 +572,292 @@ func (g *GameDrawer) HandleNetworkMessage(message network.Message) {
 			}
 		}
 	case network.Shutdown:
-		if _, ok := g.remoteData.clients[message.NetworkAddr]; ok {
-			g.addMessageWithLevel("Lost connection...", warning)
-			g.addMessageWithLevel(fmt.Sprintf("%s disconnected", message.NetworkAddr), warning)
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok && !client.migrated {
 			delete(g.remoteData.clients, message.NetworkAddr)
+			g.Game.Events.Publish(pkg.PeerLeft{Addr: message.NetworkAddr})
 			g.updateCurrentState(pkg.StartGame)
 		}
+	case network.Resume:
+		if _, ok := g.remoteData.clients[message.NetworkAddr]; ok && g.Game.IsRemoteClient() {
+			snapshot, err := network.DecodeValue[pkg.Snapshot](message)
+			if err != nil {
+				g.logger.Warn("fail to decode the match snapshot", "addr", message.NetworkAddr, "err", err)
+				return
+			}
+			g.Game.Restore(snapshot)
+			g.stopRollback()
+			g.addMessageWithLevel(fmt.Sprintf("Match resumed (%d/%d)", g.Game.PlayerL.Score, g.Game.PlayerR.Score), info)
+		}
+	case network.Seed:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			if g.Game.IsRemoteServer() {
+				g.violation(client, message, "the seed belongs to the host")
+				return
+			}
+			value, _ := message.Data.Value.(string)
+			seed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || g.Game.CurrentState() != pkg.StartGame {
+				return
+			}
+			g.Game.NewMatch(seed)
+		}
+	case network.Session:
+		if _, ok := g.remoteData.clients[message.NetworkAddr]; ok && g.Game.IsRemoteClient() {
+			g.remoteData.session, _ = message.Data.Value.(string)
+		}
+	case network.Swap:
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			value, ok := message.Data.Value.(string)
+			if !ok {
+				if g.Game.IsRemoteServer() {
+					g.violation(client, message, "invalid value")
+				}
+				return
+			}
+			// the players swap the sides between two games only
+			if g.Game.CurrentState() != pkg.StartGame {
+				return
+			}
+			switch {
+			case value == network.SwapRequest && g.remoteData.swap.local && g.Game.IsRemoteServer():
+				g.swapSides()
+				g.send(network.NewMessage(network.Swap.String(), g.Game.HostSide.String()))
+			case value == network.SwapRequest:
+				g.remoteData.swap.remote = true
+				if !g.remoteData.swap.local {
+					g.addMessageWithLevel(fmt.Sprintf("%s wants to swap the sides, press [F5] to accept", g.remotePlayer().Name), info)
+				}
+			case g.Game.IsRemoteServer():
+				g.violation(client, message, "the sides belong to the host")
+			default:
+				if side := pkg.ToPlayerSide(value); side != -1 && side != g.Game.HostSide {
+					g.swapSides()
+				}
+			}
+		}
 	case network.Subscribe:
+		token, capabilities := "", []string(nil)
 		if g.Game.IsRemoteClient() {
-			if message.Data.Value.(string) != "accepted" {
+			welcome, err := network.DecodeValue[network.Welcome](message)
+			if err != nil {
+				// the servers older than the handshake only send a string status
+				welcome = network.NewRefusal("outdated server")
+			}
+			if welcome.Status != network.Accepted {
 				g.addMessageWithLevel("Connection refused...", warning)
+				if welcome.Reason != "" {
+					g.addMessageWithLevel(welcome.Reason, warning)
+				}
 				return
 			}
+			if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+				g.resumeSession(client, message.NetworkAddr)
+				return
+			}
+			capabilities = welcome.Capabilities
+			if welcome.Rules != nil {
+				g.applyRules(*welcome.Rules)
+			}
+			if slices.Contains(capabilities, network.CapabilityRollback) {
+				g.netcode.settings = welcome.Rollback
+			}
+			if slices.Contains(capabilities, network.CapabilitySeed) {
+				g.Game.NewMatch(welcome.Seed)
+			}
+			g.addMessageWithLevel(fmt.Sprintf("Protocol v%d %v", welcome.Protocol, capabilities), logg)
 			g.addMessageWithLevel("Press [space] to start...", info)
 		} else {
+			hello, err := network.DecodeValue[network.Hello](message)
+			if err != nil || hello.Protocol == 0 {
+				// the clients older than the handshake only understand a string status
+				g.addMessageWithLevel(fmt.Sprintf("%s refused: outdated client", message.NetworkAddr), warning)
+				g.send(network.NewMessage(network.Subscribe.String(), network.Refused).WithAddr(message.NetworkAddr))
+				return
+			}
+			capabilities, err = network.Negotiate(hello)
+			if err != nil {
+				g.addMessageWithLevel(fmt.Sprintf("%s refused: %v", message.NetworkAddr, err), warning)
+				g.send(network.NewMessage(network.Subscribe.String(), network.NewRefusal(err.Error())).WithAddr(message.NetworkAddr))
+				return
+			}
+			if client := g.remoteData.findByToken(hello.Token); client != nil {
+				g.resumeSession(client, message.NetworkAddr)
+				return
+			}
 			if len(g.remoteData.clients) > 0 {
-				g.send(network.NewMessage(network.Subscribe.String(), "refused").WithAddr(message.NetworkAddr))
+				g.addMessageWithLevel(fmt.Sprintf("%s refused: the game is full", message.NetworkAddr), warning)
+				g.send(network.NewMessage(network.Subscribe.String(), network.NewRefusal("the game is full")).WithAddr(message.NetworkAddr))
 				return
 			}
-			g.addMessageWithLevel("New subscriber...", logg)
-			g.addMessageWithLevel(fmt.Sprintf("%s connected", message.NetworkAddr), logg)
-			g.send(network.NewMessage(network.Subscribe.String(), "accepted").WithAddr(message.NetworkAddr))
+			if g.Game.HostSide != pkg.PlayerLeft && !slices.Contains(capabilities, network.CapabilityRoles) {
+				// the clients without roles always play on the right
+				g.swapSides()
+			}
+			g.Game.Events.Publish(pkg.PeerJoined{Addr: message.NetworkAddr})
+			g.send(network.NewMessage(network.Subscribe.String(), g.welcome(capabilities)).WithAddr(message.NetworkAddr))
+
+			token = newSessionToken()
+			g.send(network.NewMessage(network.Session.String(), token).WithAddr(message.NetworkAddr))
 		}
 		g.remoteData.clients[message.NetworkAddr] = newRemoteClient(message.NetworkAddr)
 		g.remoteData.clients[message.NetworkAddr].lastPing = time.Now()
+		g.remoteData.clients[message.NetworkAddr].token = token
+		g.remoteData.clients[message.NetworkAddr].capabilities = capabilities
+		if slices.Contains(capabilities, network.CapabilityLobby) {
+			g.send(network.NewMessage(network.Lobby.String(), g.lobbyState()).WithAddr(message.NetworkAddr))
+		}
+		if slices.Contains(capabilities, network.CapabilityRoles) && g.Game.IsRemoteServer() {
+			g.send(network.NewMessage(network.Swap.String(), g.Game.HostSide.String()).WithAddr(message.NetworkAddr))
+		}
 	case network.UpdateCurrentState:
-		if _, ok := g.remoteData.clients[message.NetworkAddr]; ok {
-			g.updateCurrentState(pkg.ToState(message.Data.Value.(string)))
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			value, _ := message.Data.Value.(string)
+			state := pkg.ToState(value)
+			if state == g.Game.CurrentState() {
+				// the state is sent again (e.g. on each set), both sides already agree
+				return
+			}
+			if err := g.Game.CanTransition(state); err != nil {
+				if g.Game.IsRemoteServer() {
+					g.violation(client, message, err.Error())
+				} else {
+					g.logger.Warn("ignore the state of the host", "addr", message.NetworkAddr, "err", err)
+				}
+				return
+			}
+			if g.Game.IsRemoteServer() && !g.legalClientTransition(state) {
+				g.violation(client, message, fmt.Sprintf("illegal transition %s -> %s", g.Game.CurrentState().String(), value))
+				return
+			}
+			g.updateCurrentState(state)
 		}
 	case network.UpdatePaddleY:
-		if _, ok := g.remoteData.clients[message.NetworkAddr]; ok {
-			g.PlayersDrawer.UpdatePaddleY(message.Data.Value)
+		if client, ok := g.remoteData.clients[message.NetworkAddr]; ok {
+			// float64 => default setting of the encoding/json decoder when unmarshaling JSON numbers
+			y, ok := message.Data.Value.(float64)
+			if !ok {
+				if g.Game.IsRemoteServer() {
+					g.violation(client, message, "invalid value")
+				}
+				return
+			}
+			if g.netcode.session != nil && g.Game.CurrentState() == pkg.PlayGame {
+				// the paddles are simulated from the inputs of the players
+				return
+			}
+			if g.Game.IsRemoteServer() {
+				paddle := g.remotePlayer().Paddle
+				minY, maxY := paddleBounds(g.Game.Screen, paddle)
+				valid, ok := client.guard.paddle(float32(y), paddle.Y, paddle.Speed, minY, maxY, ebiten.TPS(), time.Now())
+				if !ok {
+					g.violation(client, message, fmt.Sprintf("invalid paddle position %0.2f (corrected %0.2f)", y, valid))
+				}
+				y = float64(valid)
+			}
+			g.PlayersDrawer.UpdatePaddleY(float32(y))
 		}
 	}
 }
 
+// legalClientTransition returns true if a client can update the current state to the {state},
+// a client only notifies the server that it lost the ball in its half of the table during the game
+func (g *GameDrawer) legalClientTransition(state pkg.State) bool {
+	if g.Game.CurrentState() != pkg.PlayGame {
+		return false
+	}
+	if g.Game.LocalSide() == pkg.PlayerLeft {
+		return state == pkg.PlayerRLostBall && g.Game.Ball.X >= float32(g.Game.Screen.GameZoneXCenter())
+	}
+	return state == pkg.PlayerLLostBall && g.Game.Ball.X <= float32(g.Game.Screen.GameZoneXCenter())
+}
+
+// violation records an invalid input of the {client} and it kicks the client
+// if it sends too many invalid inputs
+func (g *GameDrawer) violation(client *networkClient, message network.Message, reason string) {
+	metrics.Violations.With(message.AsCMD().String()).Inc()
+	nb := client.guard.violation(time.Now())
+	g.antiCheat.Warn("invalid input", "addr", client.networkAddr, "cmd", message.Data.Cmd, "reason", reason, "violations", nb)
+
+	if nb >= maxViolations {
+		g.kick(client, reason)
+	}
+}
+
+// kick disconnects the {client} from the server
+func (g *GameDrawer) kick(client *networkClient, reason string) {
+	g.antiCheat.Warn("kick the client", "addr", client.networkAddr, "reason", reason)
+	g.addMessageWithLevel(fmt.Sprintf("%s kicked: %s", client.networkAddr, reason), warning)
+	g.send(network.NewSimpleMessage(network.Shutdown.String()).WithAddr(client.networkAddr))
+	g.HandleNetworkMessage(network.NewSimpleMessage(network.Shutdown.String()).WithAddr(client.networkAddr))
+}
+
+// disconnect marks the {client} as disconnected and pauses the match until it resumes its session
+func (g *GameDrawer) disconnect(client *networkClient) {
+	client.disconnectedAt = time.Now()
+	g.addMessageWithLevel(fmt.Sprintf("%s not responding...", client.networkAddr), warning)
+	if g.Game.CurrentState() == pkg.PlayGame || g.Game.CurrentState() == pkg.ResumeGame {
+		g.updateCurrentState(pkg.PauseGame)
+	}
+}
+
+// resumeSession resumes the session of the {client} from the {networkAddr} address
+// and the server sends it the full current state of the match
+func (g *GameDrawer) resumeSession(client *networkClient, networkAddr string) {
+	if client.networkAddr != networkAddr {
+		if g.Game.IsRemoteServer() {
+			// release the subscriber of the previous address
+			g.send(network.NewSimpleMessage(network.Shutdown.String()).WithAddr(client.networkAddr))
+		}
+		delete(g.remoteData.clients, client.networkAddr)
+		metrics.ClientRTT.Delete(client.networkAddr)
+		metrics.PingFailures.Delete(client.networkAddr)
+		client.networkAddr = networkAddr
+		g.remoteData.clients[networkAddr] = client
+	}
+	client.disconnectedAt = time.Time{}
+	client.migrated = false
+	if client.token == "" {
+		client.token = newSessionToken()
+	}
+	client.guard.resetPaddle()
+	client.nbPingAttempts = 0
+	// the set goes on without the rollback netcode from the snapshot of the match
+	g.stopRollback()
+	client.lastPing = time.Now()
+
+	if g.Game.IsRemoteServer() {
+		g.send(network.NewMessage(network.Subscribe.String(), g.welcome(client.capabilities)).WithAddr(networkAddr))
+		g.send(network.NewMessage(network.Session.String(), client.token).WithAddr(networkAddr))
+		g.send(network.NewMessage(network.Resume.String(), g.Game.Snapshot()).WithAddr(networkAddr))
+	}
+	if slices.Contains(client.capabilities, network.CapabilityLobby) {
+		g.send(network.NewMessage(network.Lobby.String(), g.lobbyState()).WithAddr(networkAddr))
+	}
+	g.addMessageWithLevel(fmt.Sprintf("%s resumed the session", networkAddr), info)
+}
+
+// welcome builds the answer which accepts a client with the negotiated {capabilities}
+func (g *GameDrawer) welcome(capabilities []string) network.Welcome {
+	var rules *network.Rules
+	if slices.Contains(capabilities, network.CapabilityRules) {
+		current := g.rules()
+		rules = &current
+	}
+	var rollback *network.Rollback
+	if slices.Contains(capabilities, network.CapabilityRollback) {
+		rollback = g.netcode.settings
+	}
+	return network.NewWelcome(capabilities, rules, rollback, g.Game.Random.Seed())
+}
+
+// newMatch chooses the seed of the next match and the host shares it with the client
+func (g *GameDrawer) newMatch() {
+	g.Game.NewMatch(pkg.NewSeed())
+	if client := g.peerWith(network.CapabilitySeed); client != nil && g.Game.IsRemoteServer() {
+		g.send(network.NewMessage(network.Seed.String(), strconv.FormatUint(g.Game.Random.Seed(), 10)).WithAddr(client.networkAddr))
+	}
+}
+
 func (g *GameDrawer) playerWinSet(player *pkg.Player) {
 	g.Game.Mark(player)
 	g.Game.EndSet(*player)
@@ -401,49 +892,49 @@ func (g *GameDrawer) drawBackgroundZone(screen *ebiten.Image) {
 		DrawRectangle(screen,
 			int(titleX)-titleXMargin-10, 20,
 			pkg.Position{X: float32(xLeft/2) - 40, Y: float32(yBottom/2) - 40},
-			g.Game.Screen.AvailableColors["#atari1"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent1])
 
 		DrawRectangle(screen,
 			int(titleX)-titleXMargin-40, 20,
 			pkg.Position{X: float32(xLeft/2) - 10, Y: float32(yBottom/2) - 10},
-			g.Game.Screen.AvailableColors["#atari2"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent2])
 
 		DrawRectangle(screen,
 			int(titleX)-titleXMargin-70, 20,
 			pkg.Position{X: float32(xLeft/2) + 20, Y: float32(yBottom/2) + 20},
-			g.Game.Screen.AvailableColors["#atari3"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent3])
 
 		// right lines
 		DrawRectangle(screen,
 			g.Game.Screen.Width/2, 20,
 			pkg.Position{X: float32(g.Game.Screen.Width/2) + float32(titleSize/2) + 15, Y: float32(yBottom/2) - 40},
-			g.Game.Screen.AvailableColors["#atari1"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent1])
 
 		DrawRectangle(screen,
 			g.Game.Screen.Width/2, 20,
 			pkg.Position{X: float32(g.Game.Screen.Width/2) + float32(titleSize/2) + 15, Y: float32(yBottom/2) - 10},
-			g.Game.Screen.AvailableColors["#atari2"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent2])
 
 		DrawRectangle(screen,
 			g.Game.Screen.Width/2, 20,
 			pkg.Position{X: float32(g.Game.Screen.Width/2) + float32(titleSize/2) + 15, Y: float32(yBottom/2) + 20},
-			g.Game.Screen.AvailableColors["#atari3"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent3])
 
 		// vertical lines
 		DrawRectangle(screen,
 			20, g.Game.Screen.Height,
 			pkg.Position{X: float32(xLeft/2) - 40, Y: float32(yBottom/2) - 20},
-			g.Game.Screen.AvailableColors["#atari1"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent1])
 
 		DrawRectangle(screen,
 			20, g.Game.Screen.Height,
 			pkg.Position{X: float32(xLeft/2) - 10, Y: float32(yBottom / 2)},
-			g.Game.Screen.AvailableColors["#atari2"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent2])
 
 		DrawRectangle(screen,
 			20, g.Game.Screen.Height,
 			pkg.Position{X: float32(xLeft/2) + 20, Y: float32(yBottom/2) + 20},
-			g.Game.Screen.AvailableColors["#atari3"])
+			g.Game.Screen.AvailableColors[pkg.ColorAccent3])
 	}
 
 	drawTitle := func() {
@@ -466,21 +957,23 @@ func (g *GameDrawer) drawBackgroundZone(screen *ebiten.Image) {
 		)
 	}
 
-	screen.Fill(g.Game.Screen.AvailableColors["#bg"])
+	screen.Fill(g.Game.Screen.AvailableColors[pkg.ColorBackground])
 	drawTitle()
-	drawAtariLogo()
+	if g.Game.Screen.Table.Logo {
+		drawAtariLogo()
+	}
 }
 
 // drawGameZoneTextZone draws the remote text info
 func (g *GameDrawer) drawRemoteGameZone(screen *ebiten.Image) {
-	textColor := g.Game.Screen.AvailableColors["white"]
+	textColor := g.Game.Screen.AvailableColors[pkg.ColorText]
 	font := g.Game.Screen.Font.TinyText
 	fonSize := g.Game.Screen.Font.TinyTextSize
 	marginY := 2
 
 	DrawRectangle(screen, g.Game.Screen.RemoteExtendZoneW-15, g.Game.Screen.GameZoneHeight()+30, pkg.Position{
 		X: float32(g.Game.Screen.XLeft - float32(g.Game.Screen.RemoteExtendZoneW)),
-		Y: g.Game.Screen.YBottom - 15}, color.Black)
+		Y: g.Game.Screen.YBottom - 15}, g.Game.Screen.AvailableColors[pkg.ColorPanel])
 
 	y := g.Game.Screen.YBottom
 
@@ -514,7 +1007,10 @@ func (g *GameDrawer) drawRemoteGameZone(screen *ebiten.Image) {
 		y += float32(g.Game.Screen.Font.TextSize) + float32(marginY)
 
 		text = "..."
-		if !client.lastPong.IsZero() {
+		if client.disconnected() {
+			remaining := g.remoteData.sessionGraceWindow - time.Since(client.disconnectedAt).Round(time.Second)
+			text = fmt.Sprintf("disconnected - resume %s", max(remaining, 0))
+		} else if !client.lastPong.IsZero() {
 			nbAttemptsText := ""
 			if client.nbPingAttempts > 1 {
 				nbAttemptsText = fmt.Sprintf(" (%d/%d)", client.nbPingAttempts, client.nbPingMaxAttempts)
@@ -555,8 +1051,10 @@ func (g *GameDrawer) drawRemoteGameZone(screen *ebiten.Image) {
 		y += float32(g.Game.Screen.Font.TextSize) + float32(marginY)
 	}
 
+	g.drawChat(screen, g.Game.Screen.YTop)
+
 	if g.Game.IsRemoteClient() {
-		if g.remoteData.readyToPlay.ready && g.Game.CurrentState == pkg.StartGame {
+		if g.remoteData.readyToPlay.ready && g.Game.CurrentState() == pkg.StartGame {
 			font := g.Game.Screen.Font.H2
 			fontSize := g.Game.Screen.Font.H2Size
 
@@ -572,14 +1070,14 @@ func (g *GameDrawer) drawRemoteGameZone(screen *ebiten.Image) {
 				pkg.Position{
 					X: float32(g.Game.Screen.GameZoneXCenter() - textZoneW/2),
 					Y: float32(g.Game.Screen.GameZoneYCenter() - textZoneH/2)},
-				color.Black)
+				g.Game.Screen.AvailableColors[pkg.ColorPanel])
 
 			text := "READY"
 			if nbSeconds := g.remoteData.readyToPlay.nbSeconds; nbSeconds > 0 && nbSeconds <= g.remoteData.readyToPlay.nbSecondsMax {
 				text = fmt.Sprintf("%s%s", text, strings.Repeat(".", nbSeconds))
 			}
 
-			DrawText(screen, text, font, color.White,
+			DrawText(screen, text, font, g.Game.Screen.AvailableColors[pkg.ColorText],
 				pkg.Position{
 					X: float32(g.Game.Screen.GameZoneXCenter()) - float32(GetSize(text, fontSize))/2,
 					Y: float32(g.Game.Screen.GameZoneYCenter() - fontSize/2)},
@@ -588,7 +1086,7 @@ func (g *GameDrawer) drawRemoteGameZone(screen *ebiten.Image) {
 			font = g.Game.Screen.Font.SmallText
 			fontSize = g.Game.Screen.Font.SmallTextSize
 			text = "Please wait for the server to start the game."
-			DrawText(screen, text, font, color.White,
+			DrawText(screen, text, font, g.Game.Screen.AvailableColors[pkg.ColorText],
 				pkg.Position{
 					X: float32(g.Game.Screen.GameZoneXCenter()) - float32(GetSize(text, fontSize))/2,
 					Y: float32(g.Game.Screen.GameZoneYCenter() + fontSize/2 + 20)},
@@ -597,26 +1095,6 @@ func (g *GameDrawer) drawRemoteGameZone(screen *ebiten.Image) {
 	}
 }
 
-func computeSecondFromTPS(nb int) int {
-	tps := ebiten.ActualTPS()
-	if tps > 0 {
-		millis := nb * (1000 / int(tps))
-		s := 0
-		if millis >= 1000 && millis < 2000 {
-			s = 1
-		} else if millis >= 2000 && millis < 3000 {
-			s = 2
-		} else if millis >= 3000 && millis < 4000 {
-			s = 3
-		} else if millis >= 4000 {
-			s = 4
-		}
-		return s
-	} else {
-		return 0
-	}
-}
-
 // drawGameZoneText draws the "how to play" and the players's score zone (name and score)
 func (g *GameDrawer) drawGameZoneText(screen *ebiten.Image) {
 	marginCenterX := 45
@@ -625,21 +1103,21 @@ func (g *GameDrawer) drawGameZoneText(screen *ebiten.Image) {
 	playerLText := fmt.Sprintf("%s: %d", strings.ToUpper(g.Game.PlayerL.Name), g.Game.PlayerL.Score)
 
 	// Player L score
-	DrawText(screen, playerLText, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors["white"],
+	DrawText(screen, playerLText, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors[pkg.ColorText],
 		pkg.Position{
 			X: float32(g.Game.Screen.GameZoneXCenter() - (len(playerLText) * g.Game.Screen.Font.TextSize) - marginCenterX),
 			Y: g.Game.Screen.YBottom + marginTopY},
 	)
 
 	// Player R score
-	DrawText(screen, fmt.Sprintf("%s: %d", strings.ToUpper(g.Game.PlayerR.Name), g.Game.PlayerR.Score), g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors["white"],
+	DrawText(screen, fmt.Sprintf("%s: %d", strings.ToUpper(g.Game.PlayerR.Name), g.Game.PlayerR.Score), g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors[pkg.ColorText],
 		pkg.Position{
 			X: float32(g.Game.Screen.GameZoneXCenter() + marginCenterX),
 			Y: g.Game.Screen.YBottom + marginTopY},
 	)
 
 	// display the user's info - how to play it
-	if g.Game.CurrentState == pkg.StartGame {
+	if g.Game.CurrentState() == pkg.StartGame {
 		y := int(g.Game.Screen.YBottom) + int(marginTopY) + 40
 
 		description := []string{}
@@ -652,7 +1130,7 @@ func (g *GameDrawer) drawGameZoneText(screen *ebiten.Image) {
 			fmt.Sprintf("or the first to %d points!", g.Game.Win.Score))
 
 		for _, line := range description {
-			DrawText(screen, line, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors["white"],
+			DrawText(screen, line, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors[pkg.ColorText],
 				pkg.Position{
 					X: float32(g.Game.Screen.XLeft) + 35,
 					Y: float32(y)},
@@ -671,10 +1149,10 @@ func (g *GameDrawer) drawGameZoneText(screen *ebiten.Image) {
 		if g.Game.IsRemoteClient() {
 			description = append(description, "Press [space] when you are ready", "to start the game...")
 		} else {
-			description = append(description, "Press [space] to start or pause", "at every moment...")
+			description = append(description, "Press [space] to start and", "[esc] to pause the game...")
 		}
 		for _, line := range description {
-			DrawText(screen, line, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors["white"],
+			DrawText(screen, line, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors[pkg.ColorText],
 				pkg.Position{
 					X: float32(g.Game.Screen.GameZoneXCenter()) + 20,
 					Y: float32(y)},
@@ -689,27 +1167,35 @@ func (g *GameDrawer) drawBackgroundGameZone(screen *ebiten.Image) {
 	DrawRectangle(screen,
 		g.Game.Screen.GameZoneWidth(), g.Game.Screen.GameZoneHeight(),
 		pkg.Position{X: float32(g.Game.Screen.XLeft), Y: float32(g.Game.Screen.YBottom)},
-		g.Game.Screen.AvailableColors["#table-bg"])
+		g.Game.Screen.AvailableColors[pkg.ColorTable])
 
-	DrawRectangle(screen,
-		g.Game.Screen.GameZoneWidth()-10, g.Game.Screen.GameZoneHeight()-10,
-		pkg.Position{X: float32(g.Game.Screen.XLeft) + 5, Y: float32(g.Game.Screen.YBottom + 5)},
-		g.Game.Screen.AvailableColors["white"])
+	line := g.Game.Screen.AvailableColors[pkg.ColorTableLine]
 
-	DrawRectangle(screen,
-		g.Game.Screen.GameZoneWidth()-40, g.Game.Screen.GameZoneHeight()-30,
-		pkg.Position{X: float32(g.Game.Screen.XLeft) + 20, Y: float32(g.Game.Screen.YBottom + 15)},
-		g.Game.Screen.AvailableColors["#table-bg"])
+	if g.Game.Screen.Table.Border {
+		DrawRectangle(screen,
+			g.Game.Screen.GameZoneWidth()-10, g.Game.Screen.GameZoneHeight()-10,
+			pkg.Position{X: float32(g.Game.Screen.XLeft) + 5, Y: float32(g.Game.Screen.YBottom + 5)},
+			line)
+
+		DrawRectangle(screen,
+			g.Game.Screen.GameZoneWidth()-40, g.Game.Screen.GameZoneHeight()-30,
+			pkg.Position{X: float32(g.Game.Screen.XLeft) + 20, Y: float32(g.Game.Screen.YBottom + 15)},
+			g.Game.Screen.AvailableColors[pkg.ColorTable])
+	}
 
-	vector.DrawFilledCircle(screen, float32(g.Game.Screen.GameZoneXCenter()), float32(g.Game.Screen.GameZoneYCenter()), float32(70), g.Game.Screen.AvailableColors["white"], true)
-	vector.DrawFilledCircle(screen, float32(g.Game.Screen.GameZoneXCenter()), float32(g.Game.Screen.GameZoneYCenter()), float32(65), g.Game.Screen.AvailableColors["#bg"], true)
-	vector.DrawFilledCircle(screen, float32(g.Game.Screen.GameZoneXCenter()), float32(g.Game.Screen.GameZoneYCenter()), float32(30), g.Game.Screen.AvailableColors["white"], true)
-	vector.DrawFilledCircle(screen, float32(g.Game.Screen.GameZoneXCenter()), float32(g.Game.Screen.GameZoneYCenter()), float32(25), g.Game.Screen.AvailableColors["#bg"], true)
+	if g.Game.Screen.Table.CenterCircle {
+		vector.DrawFilledCircle(screen, float32(g.Game.Screen.GameZoneXCenter()), float32(g.Game.Screen.GameZoneYCenter()), float32(70), line, true)
+		vector.DrawFilledCircle(screen, float32(g.Game.Screen.GameZoneXCenter()), float32(g.Game.Screen.GameZoneYCenter()), float32(65), g.Game.Screen.AvailableColors[pkg.ColorBackground], true)
+		vector.DrawFilledCircle(screen, float32(g.Game.Screen.GameZoneXCenter()), float32(g.Game.Screen.GameZoneYCenter()), float32(30), line, true)
+		vector.DrawFilledCircle(screen, float32(g.Game.Screen.GameZoneXCenter()), float32(g.Game.Screen.GameZoneYCenter()), float32(25), g.Game.Screen.AvailableColors[pkg.ColorBackground], true)
+	}
 
-	DrawRectangle(screen,
-		5, g.Game.Screen.GameZoneHeight()-20,
-		pkg.Position{X: float32(g.Game.Screen.GameZoneXCenter()) - 2.5, Y: g.Game.Screen.YBottom + 10},
-		g.Game.Screen.AvailableColors["white"])
+	if g.Game.Screen.Table.CenterLine {
+		DrawRectangle(screen,
+			5, g.Game.Screen.GameZoneHeight()-20,
+			pkg.Position{X: float32(g.Game.Screen.GameZoneXCenter()) - 2.5, Y: g.Game.Screen.YBottom + 10},
+			line)
+	}
 }
 
 // drawWinnerGameZone draws the winner player zone and game stats
@@ -728,41 +1214,48 @@ func (g *GameDrawer) drawWinnerGameZone(screen *ebiten.Image) {
 		}
 
 		// draw the Winner info
-		DrawText(screen, "And the winner", textFont, g.Game.Screen.AvailableColors["white"],
+		DrawText(screen, "And the winner", textFont, g.Game.Screen.AvailableColors[pkg.ColorText],
 			pkg.Position{
 				X: float32(x+direction) - float32((len("And the winner")*textSize))/2,
 				Y: float32(g.Game.Screen.GameZoneYCenter() - marginTitleSize)},
 		)
-		DrawText(screen, "is...", textFont, g.Game.Screen.AvailableColors["white"],
+		DrawText(screen, "is...", textFont, g.Game.Screen.AvailableColors[pkg.ColorText],
 			pkg.Position{
 				X: float32(x+direction) - float32((len("is...")*textSize))/2,
 				Y: float32(g.Game.Screen.GameZoneYCenter() - marginTitleSize + marginTextSize + textSize)},
 		)
 
-		DrawText(screen, strings.ToUpper(player.Name), textFont, g.Game.Screen.AvailableColors["white"],
+		DrawText(screen, strings.ToUpper(player.Name), textFont, g.Game.Screen.AvailableColors[pkg.ColorText],
 			pkg.Position{
 				X: float32(x+direction) - float32((len(player.Name)*textSize))/2,
 				Y: float32(g.Game.Screen.GameZoneYCenter() - textSize/2)},
 		)
+		if g.Game.Win.Forfeit != nil {
+			DrawText(screen, "by forfeit", g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors[pkg.ColorText],
+				pkg.Position{
+					X: float32(x+direction) - float32((len("by forfeit")*g.Game.Screen.Font.TextSize))/2,
+					Y: float32(g.Game.Screen.GameZoneYCenter() + textSize/2 + marginTextSize)},
+			)
+		}
 
 		// draw the stats players
-		DrawText(screen, "Stats", textFont, g.Game.Screen.AvailableColors["white"],
+		DrawText(screen, "Stats", textFont, g.Game.Screen.AvailableColors[pkg.ColorText],
 			pkg.Position{
 				X: float32(x+(direction*-1)) - float32((len("Stats")*textSize))/2,
 				Y: float32(g.Game.Screen.GameZoneYCenter() - marginTitleSize)},
 		)
 
 		if len(g.Game.Win.Sets) > 0 {
-			totalTime := g.Game.Win.Sets[len(g.Game.Win.Sets)-1].EndTime.Sub(g.Game.Win.Sets[0].StartTime)
-			totalTimeToDisplay := fmt.Sprintf("Time: %s", time.Unix(0, 0).UTC().Add(totalTime).Format("04:05"))
-			DrawText(screen, totalTimeToDisplay, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors["white"],
+			totalTime := g.Game.Win.Sets[len(g.Game.Win.Sets)-1].EndTime - g.Game.Win.Sets[0].StartTime
+			totalTimeToDisplay := fmt.Sprintf("Time: %s", pkg.FormatDuration(totalTime))
+			DrawText(screen, totalTimeToDisplay, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors[pkg.ColorText],
 				pkg.Position{
 					X: float32(x+(direction*-1)) - float32((len(totalTimeToDisplay)*g.Game.Screen.Font.TextSize))/2,
 					Y: float32(g.Game.Screen.GameZoneYCenter() - g.Game.Screen.Font.TextSize - marginTextSize)},
 			)
 
 			maxXSpeedToDisplay := fmt.Sprintf("Max speed: %0.02f", g.Game.MaxXSpeedSet())
-			DrawText(screen, maxXSpeedToDisplay, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors["white"],
+			DrawText(screen, maxXSpeedToDisplay, g.Game.Screen.Font.Text, g.Game.Screen.AvailableColors[pkg.ColorText],
 				pkg.Position{
 					X: float32(x+(direction*-1)) - float32((len(maxXSpeedToDisplay)*g.Game.Screen.Font.TextSize))/2,
 					Y: float32(g.Game.Screen.GameZoneYCenter() + marginTextSize)},
@@ -770,17 +1263,21 @@ func (g *GameDrawer) drawWinnerGameZone(screen *ebiten.Image) {
 		}
 
 		y := g.Game.Screen.GameZoneYCenter() + marginTextSize*3
+		if g.Game.Win.Forfeit != nil {
+			y += g.Game.Screen.Font.TextSize + marginTextSize
+		}
 		toTextSize := -1
 		for _, set := range g.Game.Win.Sets {
 			y += 15
 
-			color := g.Game.Screen.AvailableColors["#bg"]
+			// the sets are marked won (W) or lost (L) by the winner with a text, not only with a colour
+			color, mark := g.Game.Screen.AvailableColors[pkg.ColorBackground], "L"
 			if set.HasWin(*player) {
-				color = g.Game.Screen.AvailableColors["white"]
+				color, mark = g.Game.Screen.AvailableColors[pkg.ColorText], "W"
 			}
 
 			textSize := g.Game.Screen.Font.SmallTextSize
-			toText := fmt.Sprintf("T. %s X. %s", set.Duration(), set.SpeedFormat())
+			toText := fmt.Sprintf("%s T. %s X. %s", mark, set.Duration(), set.SpeedFormat())
 			if toTextSize == -1 {
 				toTextSize = len(toText) * textSize
 			}
@@ -789,7 +1286,7 @@ func (g *GameDrawer) drawWinnerGameZone(screen *ebiten.Image) {
 				X: float32(x + direction - textSize/2 - textSize - toTextSize/2),
 				Y: float32(y)}, color)
 
-			DrawText(screen, toText, g.Game.Screen.Font.SmallText, g.Game.Screen.AvailableColors["white"],
+			DrawText(screen, toText, g.Game.Screen.Font.SmallText, g.Game.Screen.AvailableColors[pkg.ColorText],
 				pkg.Position{
 					X: float32(x + direction + textSize - toTextSize/2),
 					Y: float32(y)},
@@ -800,17 +1297,42 @@ func (g *GameDrawer) drawWinnerGameZone(screen *ebiten.Image) {
 }
 
 func (g *GameDrawer) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return g.Game.Screen.Width, g.Game.Screen.Height
+	return g.letterbox.Layout(g.Game.Screen, outsideWidth, outsideHeight)
 }
 
-func (g *GameDrawer) addMessageWithLevel(msg string, level networkMessageLevel) {
-	maxSize := 40
-	if len(g.remoteData.messages) > maxSize {
-		g.remoteData.messages = g.remoteData.messages[len(g.remoteData.messages)-maxSize:]
+// Announcement represents the state of the hosted game announced on the LAN
+type Announcement struct {
+	Host      string
+	Rules     network.Rules
+	FreeSlots int
+}
+
+// Announcement returns the last state of the game published by the game loop,
+// it is safe to call it from another goroutine (e.g. the LAN announcer)
+func (g *GameDrawer) Announcement() Announcement {
+	if announcement := g.announcement.Load(); announcement != nil {
+		return *announcement
 	}
+	return Announcement{}
+}
 
-	g.remoteData.messages = append(
-		g.remoteData.messages,
-		networkMessage{dateTime: fmt.Sprintf("[%s]", time.Now().Format("15:04:05")), text: msg, level: level},
-	)
+// publishAnnouncement publishes the state of the game announced on the LAN when it changes
+func (g *GameDrawer) publishAnnouncement() {
+	announcement := Announcement{Host: g.Game.PlayerL.Name, Rules: g.rules(), FreeSlots: g.freeSlots()}
+	if last := g.announcement.Load(); last == nil || *last != announcement {
+		g.announcement.Store(&announcement)
+	}
+}
+
+// freeSlots returns the number of players who can still join the game hosted by the server
+func (g *GameDrawer) freeSlots() int {
+	if len(g.remoteData.clients) > 0 {
+		return 0
+	}
+	return 1
+}
+
+// addMessageWithLevel logs the {msg} through the [CHANNEL] logger to display it to the user
+func (g *GameDrawer) addMessageWithLevel(msg string, level slog.Level) {
+	g.channel.Log(context.Background(), level, msg)
 }
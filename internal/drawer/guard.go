@@ -0,0 +1,156 @@
+package drawer
+
+import (
+	"math"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/network"
+)
+
+const (
+	// maxViolations is the number of violations before to kick a client
+	maxViolations = 5
+	// violationsWindow is the time after which the violations of a client are forgotten
+	violationsWindow = 10 * time.Second
+	// paddleBurstTicks is the number of ticks of displacement a paddle can save (network jitter)
+	paddleBurstTicks = 5
+)
+
+// rate represents the rate limit of a command (messages per second and burst)
+type rate struct {
+	perSecond float64
+	burst     float64
+}
+
+// rateLimits are the rate limits of the commands sent by a client
+var rateLimits = map[network.CMD]rate{
+	network.UpdatePaddleY:      {perSecond: 90, burst: 30},
+	network.Input:              {perSecond: 90, burst: 30},
+	network.UpdateCurrentState: {perSecond: 2, burst: 5},
+	network.Ready:              {perSecond: 2, burst: 5},
+	network.Notify:             {perSecond: 2, burst: 5},
+	network.Chat:               {perSecond: 0.5, burst: 3},
+	network.Lobby:              {perSecond: 5, burst: 10},
+	network.Pause:              {perSecond: 1, burst: 3},
+	network.Menu:               {perSecond: 5, burst: 10},
+	network.Forfeit:            {perSecond: 1, burst: 3},
+	network.Swap:               {perSecond: 1, burst: 3},
+	network.Seed:               {perSecond: 1, burst: 3},
+	network.Ping:               {perSecond: 1, burst: 5},
+	network.Pong:               {perSecond: 1, burst: 5},
+	network.Subscribe:          {perSecond: 1, burst: 5},
+}
+
+// defaultRateLimit is the rate limit of the commands without specific limit
+var defaultRateLimit = rate{perSecond: 10, burst: 20}
+
+// bucket represents the tokens available to send a command
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// newBucket builds a new full {bucket} type
+func newBucket(limit rate, now time.Time) *bucket {
+	return &bucket{tokens: limit.burst, at: now}
+}
+
+// take refills the bucket at the {limit} rate and it returns true if a token is available
+func (b *bucket) take(limit rate, now time.Time) bool {
+	b.tokens = math.Min(limit.burst, b.tokens+now.Sub(b.at).Seconds()*limit.perSecond)
+	b.at = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// guard validates the inputs of a client on the server side
+type guard struct {
+	buckets map[network.CMD]*bucket
+
+	paddleY      float32
+	paddleAt     time.Time
+	paddleBudget float32
+
+	violations      int
+	lastViolationAt time.Time
+}
+
+// newGuard builds a new {guard} type
+func newGuard() *guard {
+	return &guard{buckets: make(map[network.CMD]*bucket)}
+}
+
+// allow returns true if the client does not exceed the rate limit of the {cmd} command
+func (g *guard) allow(cmd network.CMD, now time.Time) bool {
+	limit, ok := rateLimits[cmd]
+	if !ok {
+		limit = defaultRateLimit
+	}
+
+	b, ok := g.buckets[cmd]
+	if !ok {
+		b = newBucket(limit, now)
+		g.buckets[cmd] = b
+	}
+	return b.take(limit, now)
+}
+
+// paddle checks the {y} position of the paddle against the displacement allowed since
+// the last update ({speed} per tick) and the {min}/{max} bounds of the table,
+// it returns the valid position and false if the position had to be corrected
+func (g *guard) paddle(y, current, speed, min, max float32, tps int, now time.Time) (float32, bool) {
+	burst := paddleBurstTicks * speed
+	if g.paddleAt.IsZero() {
+		g.paddleY = current
+		g.paddleAt = now
+		g.paddleBudget = burst
+	}
+
+	valid := true
+	if math.IsNaN(float64(y)) || math.IsInf(float64(y), 0) {
+		y, valid = g.paddleY, false
+	}
+
+	// the displacement budget is refilled at the speed of the paddle
+	ticks := float32(now.Sub(g.paddleAt).Seconds() * float64(tps))
+	g.paddleBudget = float32(math.Min(float64(burst), float64(g.paddleBudget+ticks*speed)))
+	if displacement := float32(math.Abs(float64(y - g.paddleY))); displacement > g.paddleBudget {
+		if y > g.paddleY {
+			y = g.paddleY + g.paddleBudget
+		} else {
+			y = g.paddleY - g.paddleBudget
+		}
+		g.paddleBudget, valid = 0, false
+	} else {
+		g.paddleBudget -= displacement
+	}
+
+	if y < min {
+		y, valid = min, false
+	} else if y > max {
+		y, valid = max, false
+	}
+
+	g.paddleY = y
+	g.paddleAt = now
+	return y, valid
+}
+
+// resetPaddle forgets the last position of the paddle (new set or resumed session)
+func (g *guard) resetPaddle() {
+	g.paddleAt = time.Time{}
+}
+
+// violation records a violation and it returns the number of recent violations of the client
+func (g *guard) violation(now time.Time) int {
+	if now.Sub(g.lastViolationAt) > violationsWindow {
+		g.violations = 0
+	}
+	g.violations++
+	g.lastViolationAt = now
+	return g.violations
+}
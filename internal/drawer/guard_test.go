@@ -0,0 +1,73 @@
+package drawer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/network"
+)
+
+func TestGuardRateLimit(t *testing.T) {
+	g := newGuard()
+	now := time.Now()
+
+	limit := rateLimits[network.Ready]
+	for i := 0; i < int(limit.burst); i++ {
+		if !g.allow(network.Ready, now) {
+			t.Fatalf("message %d should be allowed by the burst", i)
+		}
+	}
+	if g.allow(network.Ready, now) {
+		t.Fatal("message should exceed the rate limit")
+	}
+	if !g.allow(network.Ready, now.Add(time.Second)) {
+		t.Fatal("message should be allowed once the bucket is refilled")
+	}
+}
+
+func TestGuardPaddle(t *testing.T) {
+	g := newGuard()
+	now := time.Now()
+
+	// one tick at 10 px per tick
+	if y, ok := g.paddle(110, 100, 10, 0, 500, 60, now); !ok || y != 110 {
+		t.Fatalf("got %0.2f %t, want 110 true", y, ok)
+	}
+
+	// a teleport is corrected with the remaining budget
+	if y, ok := g.paddle(400, 100, 10, 0, 500, 60, now); ok || y != 150 {
+		t.Fatalf("got %0.2f %t, want 150 false", y, ok)
+	}
+
+	// the out of bounds position is corrected
+	if y, ok := g.paddle(140, 100, 10, 145, 500, 60, now.Add(time.Second)); ok || y != 145 {
+		t.Fatalf("got %0.2f %t, want 145 false", y, ok)
+	}
+}
+
+func TestGuardPaddleWithReorderedMessages(t *testing.T) {
+	g := newGuard()
+	now := time.Now()
+	tick := time.Second / 60
+
+	g.paddle(100, 100, 10, 0, 500, 60, now)
+	for i, y := range []float32{120, 110, 130, 140} {
+		if _, ok := g.paddle(y, 100, 10, 0, 500, 60, now.Add(time.Duration(i+1)*tick)); !ok {
+			t.Fatalf("reordered position %0.2f should be allowed", y)
+		}
+	}
+}
+
+func TestGuardViolations(t *testing.T) {
+	g := newGuard()
+	now := time.Now()
+
+	for i := 1; i <= 3; i++ {
+		if nb := g.violation(now); nb != i {
+			t.Fatalf("got %d violations, want %d", nb, i)
+		}
+	}
+	if nb := g.violation(now.Add(violationsWindow + time.Second)); nb != 1 {
+		t.Fatalf("got %d violations, want the old ones to be forgotten", nb)
+	}
+}
@@ -0,0 +1,280 @@
+package drawer
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/internal/network/udp"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+const waitTimeout = 3 * time.Second
+
+// peer represents a game (server or client) connected to the loopback network without window
+type peer struct {
+	drawer *GameDrawer
+	conn   network.Conn
+
+	messages chan network.Message
+	actions  chan func()
+	handled  chan network.Message
+	done     chan bool
+}
+
+// newServerPeer starts a {udp.UDPServer} on a loopback random port and a drawer to handle its messages,
+// it returns the peer and the server address
+func newServerPeer(t *testing.T) (*peer, string) {
+	t.Helper()
+	return newSecuredServerPeer(t, "")
+}
+
+// newSecuredServerPeer starts a server peer which room is secured by the {secret}
+func newSecuredServerPeer(t *testing.T, secret string) (*peer, string) {
+	t.Helper()
+
+	server, err := udp.NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secured, err := server.WithSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newPeer(t, pkg.RemoteServerMode, secured, "test-server"), server.LocalAddr().String()
+}
+
+// newClientPeer starts a {udp.UDPClient} connected to {addr} and a drawer to handle its messages
+func newClientPeer(t *testing.T, addr string) *peer {
+	t.Helper()
+	return newSecuredClientPeer(t, addr, "")
+}
+
+// newSecuredClientPeer starts a client peer which joins the room secured by the {secret}
+func newSecuredClientPeer(t *testing.T, addr, secret string) *peer {
+	t.Helper()
+	return newSessionClientPeer(t, addr, secret, "")
+}
+
+// newSessionClientPeer starts a client peer which resumes the seat of the {token} session
+func newSessionClientPeer(t *testing.T, addr, secret, token string) *peer {
+	t.Helper()
+
+	client, err := udp.NewClient(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newPeer(t, pkg.RemoteClientMode, client.WithSecret(secret).WithSession(token), "test-client")
+}
+
+func newPeer(t *testing.T, mode pkg.GameMode, conn network.Conn, version string) *peer {
+	t.Helper()
+
+	p := &peer{
+		conn:     conn,
+		messages: make(chan network.Message),
+		actions:  make(chan func()),
+		handled:  make(chan network.Message, 256),
+		done:     make(chan bool),
+	}
+	p.drawer = NewDrawerGame(pkg.NewGame(mode, false),
+		func(msg network.Message) { p.conn.Send(msg) }, func() { p.conn.Shutdown() }, version)
+
+	go p.run()
+	go conn.ListenAndServe(p.messages)
+
+	t.Cleanup(func() {
+		close(p.done)
+		p.conn.Shutdown()
+	})
+
+	return p
+}
+
+// withPromotion lets the client peer take over the host on a loopback random port,
+// it returns the channel which receives the address of the new server
+func (p *peer) withPromotion(t *testing.T) <-chan string {
+	t.Helper()
+
+	addr := make(chan string, 1)
+	p.drawer.WithPromotion(func(_ int, done func(err error)) {
+		go func() {
+			server, err := udp.NewServer("127.0.0.1:0")
+			p.actions <- func() {
+				if err == nil {
+					go p.conn.Shutdown()
+					p.conn = server
+					go server.ListenAndServe(p.messages)
+				}
+				// the next frame applies the promotion before the former host joins back
+				done(err)
+				p.drawer.handleInbox()
+				if err == nil {
+					addr <- server.LocalAddr().String()
+				}
+			}
+		}()
+	})
+	return addr
+}
+
+// run plays the goroutine of the {ebiten.Game.Update} method: it handles the network messages and the test actions
+// one at a time (in production, the network goroutine hands the messages over with {GameDrawer.Receive}
+// and {GameDrawer.Update} handles them, see TestReceiveWhileUpdating)
+func (p *peer) run() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case message := <-p.messages:
+			p.drawer.HandleNetworkMessage(message)
+			select {
+			case p.handled <- message:
+			default:
+			}
+		case action := <-p.actions:
+			action()
+		}
+	}
+}
+
+// do executes the {action} on the drawer goroutine and waits for its end
+func (p *peer) do(action func()) {
+	done := make(chan bool)
+	p.actions <- func() {
+		action()
+		close(done)
+	}
+	<-done
+}
+
+// handle injects the {message} in the drawer as if it was received from the network
+func (p *peer) handle(message network.Message) {
+	p.do(func() { p.drawer.HandleNetworkMessage(message) })
+}
+
+// waitFor waits until the drawer handles a message with the {cmd} command
+func (p *peer) waitFor(t *testing.T, cmd network.CMD) network.Message {
+	t.Helper()
+
+	timeout := time.After(waitTimeout)
+	for {
+		select {
+		case message := <-p.handled:
+			if message.AsCMD() == cmd {
+				return message
+			}
+		case <-timeout:
+			t.Fatalf("%s message not handled after %v", cmd, waitTimeout)
+		}
+	}
+}
+
+// eventually waits until the {condition} evaluated on the drawer goroutine is true
+func (p *peer) eventually(t *testing.T, condition func(g *GameDrawer) bool, msgAndArgs ...any) {
+	t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+	for time.Now().Before(deadline) {
+		ok := false
+		p.do(func() { ok = condition(p.drawer) })
+		if ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal(msgAndArgs...)
+}
+
+// hasMessage returns true if the [CHANNEL] contains the {text} message
+func (g *GameDrawer) hasMessage(text string) bool {
+	for _, msg := range g.remoteData.messages {
+		if msg.text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeWelcome decodes the answer of the server to the [Subscribe] handshake
+func decodeWelcome(t *testing.T, message network.Message) network.Welcome {
+	t.Helper()
+
+	welcome, err := network.DecodeValue[network.Welcome](message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return welcome
+}
+
+// rawClient represents a raw UDP socket which talks to the server without drawer
+type rawClient struct {
+	conn   *net.UDPConn
+	server *net.UDPAddr
+}
+
+// newRawClient opens a raw UDP socket to the {addr} server
+func newRawClient(t *testing.T, addr string) *rawClient {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	server, err := pkg.ResolveUDPAddr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &rawClient{conn: conn, server: server}
+}
+
+// send sends the {msg} to the server
+func (c *rawClient) send(t *testing.T, msg network.Message) {
+	t.Helper()
+
+	bytes, _ := jsonsutil.Marshal(msg)
+	if _, err := c.conn.WriteTo(bytes, c.server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor reads the messages from the server until a message with the {cmd} command
+func (c *rawClient) waitFor(t *testing.T, cmd network.CMD) network.Message {
+	t.Helper()
+
+	if err := c.conn.SetReadDeadline(time.Now().Add(waitTimeout)); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		buf := make([]byte, 65535)
+		size, _, err := c.conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("%s message not received: %v", cmd, err)
+		}
+		if message, err := jsonsutil.Unmarshal[network.Message](buf[:size]); err == nil && message.AsCMD() == cmd {
+			return message
+		}
+	}
+}
+
+// silent checks that the server does not answer to the client
+func (c *rawClient) silent(t *testing.T, d time.Duration) {
+	t.Helper()
+
+	if err := c.conn.SetReadDeadline(time.Now().Add(d)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 65535)
+	if size, _, err := c.conn.ReadFrom(buf); err == nil {
+		t.Fatalf("unexpected answer: %s", buf[:size])
+	}
+}
+
+// addr returns the network address of the client as seen by the server
+func (c *rawClient) addr() string {
+	return c.conn.LocalAddr().String()
+}
@@ -0,0 +1,64 @@
+package drawer
+
+import (
+	"sync"
+
+	"github.com/joakim-ribier/pong/internal/network"
+)
+
+// maxInboxMessages is the number of messages held until the next frame, the next ones are dropped as a full socket does
+const maxInboxMessages = 1024
+
+// inbox holds the messages received on the network goroutine and the results of the background tasks
+// until the next {Update}, the game is only changed on the ebiten goroutine
+type inbox struct {
+	mu       sync.Mutex
+	messages []network.Message
+	actions  []func()
+}
+
+// push holds the {message}, it returns false if the inbox is full
+func (i *inbox) push(message network.Message) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.messages) >= maxInboxMessages {
+		return false
+	}
+	i.messages = append(i.messages, message)
+	return true
+}
+
+// post holds the {action} of a background task
+func (i *inbox) post(action func()) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.actions = append(i.actions, action)
+}
+
+// drain returns the messages and the actions held since the last call
+func (i *inbox) drain() ([]network.Message, []func()) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	messages, actions := i.messages, i.actions
+	i.messages, i.actions = nil, nil
+	return messages, actions
+}
+
+// Receive hands the {message} read by the network goroutine over to the next {Update}
+func (g *GameDrawer) Receive(message network.Message) {
+	if !g.inbox.push(message) {
+		g.logger.Warn("drop the message, the game is late", "addr", message.NetworkAddr, "cmd", message.Data.Cmd)
+	}
+}
+
+// handleInbox runs the actions of the background tasks then it handles the messages received since the last frame
+// (e.g. the client becomes the host before it handles the messages of its new server)
+func (g *GameDrawer) handleInbox() {
+	messages, actions := g.inbox.drain()
+	for _, action := range actions {
+		action()
+	}
+	for _, message := range messages {
+		g.HandleNetworkMessage(message)
+	}
+}
@@ -0,0 +1,47 @@
+package drawer
+
+import (
+	"testing"
+
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// TestReceiveWhileUpdating receives the messages on a network goroutine while the game loop updates the drawer
+// as the online games do (go test -race)
+func TestReceiveWhileUpdating(t *testing.T) {
+	var sent []network.Message
+	g := NewDrawerGame(pkg.NewGame(pkg.RemoteServerMode, false), func(msg network.Message) { sent = append(sent, msg) }, func() {}, "test")
+
+	addr, y := "127.0.0.1:4000", float64(g.Game.PlayerR.Paddle.Y)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.Receive(network.NewMessage(network.Subscribe.String(), network.NewHello("")).WithAddr(addr))
+		// under the rate limit of the paddle, the subscriber is not kicked
+		for range 20 {
+			g.Receive(network.NewMessage(network.UpdatePaddleY.String(), y).WithAddr(addr))
+		}
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		if err := g.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(g.remoteData.clients) != 1 || !g.hasMessage("New subscriber...") {
+		t.Errorf("got %d clients, expected the subscriber", len(g.remoteData.clients))
+	}
+	if len(sent) == 0 {
+		t.Error("expected the welcome sent to the subscriber")
+	}
+}
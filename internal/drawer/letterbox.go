@@ -0,0 +1,59 @@
+package drawer
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// Letterbox draws the logical screen of the game scaled to fit the window,
+// the free space around it is filled with bars
+type Letterbox struct {
+	canvas   *ebiten.Image
+	viewport pkg.Viewport
+	// size of the window in pixels
+	width, height int
+	// shakeX and shakeY are the offset of the canvas (logical pixels) of a screen shake
+	shakeX, shakeY float64
+}
+
+// Layout computes the viewport of the logical {screen} in the window and it returns the size of the window in pixels
+func (l *Letterbox) Layout(screen pkg.Screen, outsideWidth, outsideHeight int) (int, int) {
+	scale := ebiten.Monitor().DeviceScaleFactor()
+	l.width, l.height = int(float64(outsideWidth)*scale), int(float64(outsideHeight)*scale)
+	l.viewport = pkg.NewViewport(screen.Width, screen.Height, l.width, l.height)
+	return l.width, l.height
+}
+
+// Draw calls {draw} on the logical canvas of the {s} screen then it draws the canvas on the window {screen}
+func (l *Letterbox) Draw(screen *ebiten.Image, s pkg.Screen, draw func(canvas *ebiten.Image)) {
+	if l.canvas == nil || l.canvas.Bounds().Dx() != s.Width || l.canvas.Bounds().Dy() != s.Height {
+		l.canvas = ebiten.NewImage(s.Width, s.Height)
+	}
+	l.canvas.Clear()
+	draw(l.canvas)
+
+	screen.Fill(s.AvailableColors[pkg.ColorPanel])
+	options := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	options.GeoM.Translate(l.shakeX, l.shakeY)
+	options.GeoM.Scale(l.viewport.Scale, l.viewport.Scale)
+	options.GeoM.Translate(l.viewport.X, l.viewport.Y)
+	screen.DrawImage(l.canvas, options)
+}
+
+// Shake moves the canvas of the next frames by the {x, y} offset in the logical coordinates
+func (l *Letterbox) Shake(x, y float64) {
+	l.shakeX, l.shakeY = x, y
+}
+
+// Size returns the size of the window in pixels
+func (l *Letterbox) Size() (int, int) {
+	return l.width, l.height
+}
+
+// UpdateFullscreen toggles the fullscreen mode with [F11]
+func UpdateFullscreen() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+	}
+}
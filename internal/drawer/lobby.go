@@ -0,0 +1,379 @@
+package drawer
+
+import (
+	"fmt"
+	"image/color"
+	"maps"
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// lobbySyncDelay is the min time between two [Lobby] messages (the name is sent while it is typed)
+const lobbySyncDelay = 250 * time.Millisecond
+
+// lobbyField is a field of the lobby edited by the player
+type lobbyField int
+
+const (
+	lobbyName lobbyField = iota
+	lobbyColor
+	lobbyScore
+	lobbySetScore
+	lobbySetGap
+)
+
+// lobby represents the choices of the local player before the game,
+// the name and the colour of its paddle and the rules of the match for the host
+type lobby struct {
+	field  lobbyField
+	colors []string
+	color  int
+	custom []rune
+	dirty  bool
+	sentAt time.Time
+}
+
+// newLobby builds a new {lobby} type with the {colors} available for the paddles
+// and the {selected} colour of the local player
+func newLobby(colors map[string]color.Color, selected string) *lobby {
+	names := slices.DeleteFunc(slices.Sorted(maps.Keys(colors)), func(name string) bool {
+		return slices.Contains(pkg.BackgroundColors, name)
+	})
+	return &lobby{colors: names, color: slices.Index(names, selected)}
+}
+
+// lobbyOpen returns true before the game when the lobby is displayed,
+// the host opens it at any time and the client once the server supports it
+func (g *GameDrawer) lobbyOpen() bool {
+	return g.Game.CurrentState() == pkg.StartGame && (g.Game.IsRemoteServer() || g.peerWith(network.CapabilityLobby) != nil)
+}
+
+// updateLobby handles the keyboard of the lobby, it returns true while the lobby captures the keyboard
+func (g *GameDrawer) updateLobby() bool {
+	if !g.lobbyOpen() {
+		return false
+	}
+	if g.Game.IsRemoteClient() && g.remoteData.readyToPlay.ready {
+		// the choices are locked once the player is ready
+		return true
+	}
+
+	last := genericsutil.When[bool, lobbyField](
+		g.Game.IsRemoteServer(), func(b bool) bool { return b },
+		func(b bool) lobbyField { return lobbySetGap },
+		func() lobbyField { return lobbyColor })
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyF5):
+		g.requestSwap()
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab), inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		g.focusLobbyField((g.lobby.field + 1) % (last + 1))
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		g.focusLobbyField((g.lobby.field + last) % (last + 1))
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		g.changeLobbyField(-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		g.changeLobbyField(1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		g.eraseLobbyField()
+	}
+
+	if chars := ebiten.AppendInputChars(nil); len(chars) > 0 {
+		g.typeLobbyField(chars)
+	}
+
+	return true
+}
+
+// focusLobbyField moves the focus of the lobby to the {field}
+func (g *GameDrawer) focusLobbyField(field lobbyField) {
+	if player := g.localPlayer(); g.lobby.field == lobbyName && player.Name == "" {
+		player.Name = defaultPlayerName(player.Side)
+		g.updateLocalPlayer()
+	}
+	g.lobby.field = field
+	g.lobby.custom = nil
+}
+
+// changeLobbyField selects the previous or next ({delta}) value of the focused field
+func (g *GameDrawer) changeLobbyField(delta int) {
+	rules := g.rules()
+	switch g.lobby.field {
+	case lobbyColor:
+		if g.Game.Screen.Palette != "" {
+			// the colour-blind palette keeps its colours
+			return
+		}
+		nb := len(g.lobby.colors)
+		g.lobby.color = (max(g.lobby.color, 0) + delta + nb) % nb
+		g.lobby.custom = nil
+		g.localPlayer().Options.Color = g.Game.Screen.AvailableColors[g.lobby.colors[g.lobby.color]]
+		g.updateLocalPlayer()
+		return
+	case lobbyScore:
+		rules.Score += delta
+	case lobbySetScore:
+		rules.SetScore += delta
+	case lobbySetGap:
+		rules.SetGapWScore += delta
+	default:
+		return
+	}
+	if rules.Valid() {
+		g.applyRules(rules)
+		g.lobby.dirty = true
+	}
+}
+
+// eraseLobbyField erases the last character of the name or of the custom colour
+func (g *GameDrawer) eraseLobbyField() {
+	switch g.lobby.field {
+	case lobbyName:
+		player := g.localPlayer()
+		if name := []rune(player.Name); len(name) > 0 {
+			player.Name = string(name[:len(name)-1])
+			g.updateLocalPlayer()
+		}
+	case lobbyColor:
+		if len(g.lobby.custom) > 0 {
+			g.lobby.custom = g.lobby.custom[:len(g.lobby.custom)-1]
+		}
+	}
+}
+
+// typeLobbyField appends the typed {chars} to the name or to the custom colour (#rrggbb)
+func (g *GameDrawer) typeLobbyField(chars []rune) {
+	switch g.lobby.field {
+	case lobbyName:
+		player := g.localPlayer()
+		name := []rune(player.Name)
+		for _, r := range chars {
+			// [space] is the ready key
+			if unicode.IsPrint(r) && !unicode.IsSpace(r) && len(name) < network.ChatNameMaxLen {
+				name = append(name, r)
+			}
+		}
+		player.Name = string(name)
+		g.updateLocalPlayer()
+	case lobbyColor:
+		if g.Game.Screen.Palette != "" {
+			return
+		}
+		for _, r := range chars {
+			if (r == '#' && len(g.lobby.custom) == 0) || (unicode.Is(unicode.ASCII_Hex_Digit, r) && len(g.lobby.custom) > 0 && len(g.lobby.custom) < len("#rrggbb")) {
+				g.lobby.custom = append(g.lobby.custom, unicode.ToLower(r))
+			}
+		}
+		if c, ok := hexToColor(string(g.lobby.custom)); ok && len(g.lobby.custom) == len("#rrggbb") {
+			if network.ColorClose(c, g.reservedColors()...) {
+				g.addMessageWithLevel(fmt.Sprintf("%s is too close to the colours of the table", string(g.lobby.custom)), warning)
+				return
+			}
+			g.lobby.color = -1
+			g.localPlayer().Options.Color = c
+			g.updateLocalPlayer()
+		}
+	}
+}
+
+// reservedColors returns the colours of the table and of the background which a paddle can not take
+func (g *GameDrawer) reservedColors() []color.Color {
+	colors := g.Game.Screen.AvailableColors
+	return []color.Color{colors[pkg.ColorTable], colors[pkg.ColorBackground]}
+}
+
+// updateLocalPlayer propagates the changes of the local player and it schedules the [Lobby] message
+func (g *GameDrawer) updateLocalPlayer() {
+	g.updatePlayerCopies(g.localPlayer())
+	g.lobby.dirty = true
+}
+
+// updatePlayerCopies propagates the name and the colour of the {player} to the drawers which hold a copy of it
+func (g *GameDrawer) updatePlayerCopies(player *pkg.Player) {
+	for _, p := range []*pkg.Player{
+		&g.PlayersDrawer.PlayerLeft, &g.PlayersDrawer.PlayerRight, &g.BallDrawer.playerL, &g.BallDrawer.playerR} {
+		if p.Side == player.Side {
+			p.Name = player.Name
+			p.Options.Color = player.Options.Color
+		}
+	}
+}
+
+// syncLobby sends the choices of the local player to the remote side if they changed
+func (g *GameDrawer) syncLobby(now time.Time) {
+	if !g.lobby.dirty || now.Sub(g.lobby.sentAt) < lobbySyncDelay {
+		return
+	}
+	g.lobby.dirty = false
+	if g.peerWith(network.CapabilityLobby) != nil {
+		g.lobby.sentAt = now
+		g.send(network.NewMessage(network.Lobby.String(), g.lobbyState()))
+	}
+}
+
+// lobbyState builds the [Lobby] message of the local player (and the rules for the host)
+func (g *GameDrawer) lobbyState() network.LobbyState {
+	player := g.localPlayer()
+	state := network.LobbyState{Profile: network.Profile{
+		Name:  genericsutil.OrElse(player.Name, func(v string) bool { return v != "" }, func() string { return defaultPlayerName(player.Side) }),
+		Color: colorToHex(player.Options.Color)}}
+	if g.Game.IsRemoteServer() {
+		rules := g.rules()
+		state.Rules = &rules
+	}
+	return state
+}
+
+// applyLobbyState applies the [Lobby] message of the remote side
+func (g *GameDrawer) applyLobbyState(state network.LobbyState) {
+	player := g.remotePlayer()
+	profile := state.Profile.Sanitize(g.reservedColors()...)
+	player.Name = genericsutil.OrElse(profile.Name, func(v string) bool { return v != "" }, func() string { return defaultPlayerName(player.Side) })
+	// the colour-blind palette keeps its colours for both players
+	if c, ok := hexToColor(profile.Color); ok && g.Game.Screen.Palette == "" {
+		player.Options.Color = c
+	}
+	g.updatePlayerCopies(player)
+
+	// the rules of the match cannot change during the game
+	if state.Rules == nil || *state.Rules == g.rules() || g.Game.CurrentState() != pkg.StartGame {
+		return
+	}
+	if !state.Rules.Valid() {
+		g.logger.Warn("invalid rules from the host", "rules", state.Rules.String())
+		return
+	}
+	g.applyRules(*state.Rules)
+	g.addMessageWithLevel(fmt.Sprintf("The host changed the rules (%s)", state.Rules), info)
+	if g.remoteData.readyToPlay.ready {
+		g.remoteData.readyToPlay.ready = false
+		g.send(network.NewMessage(network.Ready.String(), false))
+	}
+}
+
+// rules returns the rules of the current match
+func (g *GameDrawer) rules() network.Rules {
+	return network.Rules{Score: g.Game.Win.Score, SetScore: g.Game.Win.SetScore, SetGapWScore: g.Game.Win.SetGapWScore}
+}
+
+// applyRules applies the {rules} to the current match
+func (g *GameDrawer) applyRules(rules network.Rules) {
+	g.Game.Win.Score = rules.Score
+	g.Game.Win.SetScore = rules.SetScore
+	g.Game.Win.SetGapWScore = rules.SetGapWScore
+}
+
+// localPlayer returns the player of this side
+func (g *GameDrawer) localPlayer() *pkg.Player {
+	return g.Game.Player(g.Game.LocalSide())
+}
+
+// remotePlayer returns the player of the remote side
+func (g *GameDrawer) remotePlayer() *pkg.Player {
+	return g.Game.Player(g.Game.LocalSide().Other())
+}
+
+// defaultPlayerName returns the name of the player of the {side} without profile
+func defaultPlayerName(side pkg.PlayerSide) string {
+	if side == pkg.PlayerLeft {
+		return "Player L"
+	}
+	return "Player R"
+}
+
+// drawLobby draws the choices of both sides and the rules under the logo of the start screen
+func (g *GameDrawer) drawLobby(screen *ebiten.Image) {
+	font := g.Game.Screen.Font.Text
+	fontSize := g.Game.Screen.Font.TextSize
+	marginY := float32(fontSize + 10)
+	white := g.Game.Screen.AvailableColors[pkg.ColorText]
+	focus := g.Game.Screen.AvailableColors[pkg.ColorAccent3]
+	locked := g.Game.IsRemoteClient() && g.remoteData.readyToPlay.ready
+
+	line := func(field lobbyField, label, value string, pos pkg.Position) {
+		c, prefix := white, "  "
+		if g.lobby.field == field && !locked {
+			c, prefix = focus, "> "
+		}
+		DrawText(screen, fmt.Sprintf("%s%-7s%s", prefix, label, value), font, c, pos)
+	}
+	swatch := func(c color.Color, pos pkg.Position) {
+		DrawRectangle(screen, fontSize*2, fontSize, pos, c)
+	}
+
+	yTop := float32(g.Game.Screen.GameZoneYCenter()+g.Game.PlayerL.Paddle.Height/2) + 50
+	xLeft := float32(g.Game.Screen.XLeft) + 35
+	xRight := float32(g.Game.Screen.GameZoneXCenter()) + 20
+	colorX := float32(fontSize * 9)
+
+	// the local player
+	y := yTop
+	player := g.localPlayer()
+	DrawText(screen, "# YOU", font, white, pkg.Position{X: xLeft, Y: y})
+	y += marginY
+
+	cursor := ""
+	if g.lobby.field == lobbyName && !locked && time.Now().UnixMilli()/500%2 == 0 {
+		cursor = "_"
+	}
+	line(lobbyName, "NAME", player.Name+cursor, pkg.Position{X: xLeft, Y: y})
+	y += marginY
+
+	colorName := "custom"
+	if g.Game.Screen.Palette != "" {
+		colorName = g.Game.Screen.Palette
+	} else if len(g.lobby.custom) > 0 {
+		colorName = string(g.lobby.custom)
+	} else if g.lobby.color >= 0 && g.lobby.color < len(g.lobby.colors) {
+		colorName = g.lobby.colors[g.lobby.color]
+	}
+	line(lobbyColor, "COLOUR", "", pkg.Position{X: xLeft, Y: y})
+	swatch(player.Options.Color, pkg.Position{X: xLeft + colorX, Y: y})
+	DrawText(screen, colorName, font, white, pkg.Position{X: xLeft + colorX + float32(fontSize*3), Y: y})
+	y += marginY * 2
+
+	// the rules of the match, edited by the host
+	rules := g.rules()
+	DrawText(screen, "# RULES", font, white, pkg.Position{X: xLeft, Y: y})
+	y += marginY
+	line(lobbyScore, "SCORE", fmt.Sprintf("%d", rules.Score), pkg.Position{X: xLeft, Y: y})
+	y += marginY
+	line(lobbySetScore, "SET", fmt.Sprintf("%d", rules.SetScore), pkg.Position{X: xLeft, Y: y})
+	y += marginY
+	line(lobbySetGap, "GAP", fmt.Sprintf("%d", rules.SetGapWScore), pkg.Position{X: xLeft, Y: y})
+
+	// the remote player
+	y = yTop
+	DrawText(screen, "# OPPONENT", font, white, pkg.Position{X: xRight, Y: y})
+	y += marginY
+	if g.peerWith(network.CapabilityLobby) == nil {
+		DrawText(screen, "  waiting for a player...", font, white, pkg.Position{X: xRight, Y: y})
+	} else {
+		opponent := g.remotePlayer()
+		DrawText(screen, fmt.Sprintf("  %-7s%s", "NAME", opponent.Name), font, white, pkg.Position{X: xRight, Y: y})
+		y += marginY
+		DrawText(screen, fmt.Sprintf("  %-7s", "COLOUR"), font, white, pkg.Position{X: xRight, Y: y})
+		swatch(opponent.Options.Color, pkg.Position{X: xRight + colorX, Y: y})
+		y += marginY
+
+		status := "HOST"
+		if g.Game.IsRemoteServer() {
+			status = genericsutil.When[bool, string](
+				g.remoteData.readyToPlay.ready, func(b bool) bool { return b },
+				func(b bool) string { return "READY" },
+				func() string { return "NOT READY" })
+		}
+		DrawText(screen, fmt.Sprintf("  %-7s%s", "STATUS", status), font, white, pkg.Position{X: xRight, Y: y})
+	}
+
+	help := "[up/down] field - [left/right] change - [type] name or #rrggbb - [F5] swap sides"
+	DrawText(screen, help, g.Game.Screen.Font.TinyText, white,
+		pkg.Position{X: xLeft, Y: g.Game.Screen.YTop - float32(g.Game.Screen.Font.TinyTextSize) - 15})
+}
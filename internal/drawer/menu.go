@@ -0,0 +1,370 @@
+package drawer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// menuSyncDelay is the min time between two [Menu] messages (the focus moves at each key press)
+const menuSyncDelay = 200 * time.Millisecond
+
+// menuItem is an item of the pause menu
+type menuItem int
+
+const (
+	menuResume menuItem = iota
+	menuRestart
+	menuVolume
+	menuSound
+	menuFullscreen
+	menuEffects
+	menuTrail
+	menuAudioCues
+	menuStats
+	menuForfeit
+	menuMainMenu
+	menuQuit
+)
+
+// String returns the name of the item shared with the remote side
+func (i menuItem) String() string {
+	switch i {
+	case menuResume:
+		return "resume"
+	case menuRestart:
+		return "restart match"
+	case menuVolume:
+		return "volume"
+	case menuSound:
+		return "sound"
+	case menuFullscreen:
+		return "fullscreen"
+	case menuEffects:
+		return "effects"
+	case menuTrail:
+		return "ball trail"
+	case menuAudioCues:
+		return "audio cues"
+	case menuStats:
+		return "stats"
+	case menuForfeit:
+		return "forfeit"
+	case menuMainMenu:
+		return "main menu"
+	case menuQuit:
+		return "quit"
+	default:
+		return "unknown"
+	}
+}
+
+// pauseMenu represents the overlay menu displayed while the game is paused,
+// the focused item of each side and the player who paused the game
+type pauseMenu struct {
+	item     menuItem
+	stats    bool
+	pausedBy string
+	remote   string
+	quit     bool
+	back     bool
+	dirty    bool
+	sentAt   time.Time
+}
+
+// hookMenu opens the menu on the first item when the game is paused and it closes it when the game goes on
+func (g *GameDrawer) hookMenu() {
+	g.Game.OnEnter(pkg.PauseGame, func(pkg.State) {
+		g.menu.item = menuResume
+		g.menu.stats = false
+		g.menu.dirty = true
+	})
+	g.Game.OnExit(pkg.PauseGame, func(pkg.State) {
+		g.menu.pausedBy = ""
+		g.menu.remote = ""
+	})
+}
+
+// menuOpen returns true while the game is paused
+func (g *GameDrawer) menuOpen() bool {
+	return g.Game.CurrentState() == pkg.PauseGame
+}
+
+// menuItems returns the items of the menu available on this side,
+// the host only restarts the match and a player only forfeits against a remote side
+func (g *GameDrawer) menuItems() []menuItem {
+	items := []menuItem{menuResume}
+	if !g.Game.IsRemoteClient() {
+		items = append(items, menuRestart)
+	}
+	items = append(items, menuVolume, menuSound, menuFullscreen, menuEffects, menuTrail, menuAudioCues, menuStats)
+	if g.peerWith(network.CapabilityMenu) != nil {
+		items = append(items, menuForfeit)
+	}
+	return append(items, menuMainMenu, menuQuit)
+}
+
+// updateMenu pauses the game on [escape] (or it leaves the game between two matches)
+// and it handles the keyboard of the menu, it returns true while the menu captures the keyboard
+func (g *GameDrawer) updateMenu() bool {
+	if !g.menuOpen() {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			switch g.Game.CurrentState() {
+			case pkg.PlayGame:
+				g.pause(true)
+			case pkg.StartGame, pkg.WinGame:
+				g.menu.back = true
+			}
+		}
+		return false
+	}
+
+	if g.menu.stats {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			g.menu.stats = false
+			g.menu.dirty = true
+		}
+		return true
+	}
+
+	items := g.menuItems()
+	index := max(slices.Index(items, g.menu.item), 0)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab), inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		g.focusMenuItem(items[(index+1)%len(items)])
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		g.focusMenuItem(items[(index+len(items)-1)%len(items)])
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		g.changeMenuItem(items[index], -1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		g.changeMenuItem(items[index], 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		g.selectMenuItem(items[index])
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.pause(false)
+	}
+
+	return true
+}
+
+// focusMenuItem moves the focus of the menu to the {item}
+func (g *GameDrawer) focusMenuItem(item menuItem) {
+	g.menu.item = item
+	g.menu.dirty = true
+}
+
+// changeMenuItem changes the setting of the focused {item} to the previous or next ({delta}) value
+func (g *GameDrawer) changeMenuItem(item menuItem, delta int) {
+	settings := pkg.CurrentSettings()
+	switch item {
+	case menuVolume:
+		pkg.UseSettings(settings.WithVolume(float64(delta) / 10))
+	case menuSound:
+		settings.Mute = !settings.Mute
+		pkg.UseSettings(settings)
+	case menuFullscreen:
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+	case menuEffects:
+		settings.Effects = !settings.Effects
+		pkg.UseSettings(settings)
+	case menuTrail:
+		settings.HighContrastTrail = !settings.HighContrastTrail
+		pkg.UseSettings(settings)
+	case menuAudioCues:
+		settings.AudioCues = !settings.AudioCues
+		pkg.UseSettings(settings)
+	}
+}
+
+// selectMenuItem runs the action of the {item}
+func (g *GameDrawer) selectMenuItem(item menuItem) {
+	switch item {
+	case menuResume:
+		g.pause(false)
+	case menuRestart:
+		g.addMessageWithLevel("Restart the match...", info)
+		g.updateCurrentState(pkg.StartGame)
+		g.updateCurrentState(pkg.ResumeGame)
+	case menuSound, menuFullscreen, menuEffects, menuTrail, menuAudioCues:
+		g.changeMenuItem(item, 1)
+	case menuStats:
+		g.menu.stats = true
+		g.menu.dirty = true
+	case menuForfeit:
+		g.forfeit()
+	case menuMainMenu:
+		g.menu.back = true
+	case menuQuit:
+		g.menu.quit = true
+	}
+}
+
+// pause pauses (or resumes) the game, the client requests the host to do it
+func (g *GameDrawer) pause(pause bool) {
+	switch {
+	case g.Game.IsRemoteClient():
+		g.requestPause(pause)
+	case pause:
+		g.pauseBy(g.localPlayer())
+	default:
+		g.updateCurrentState(pkg.PlayGame)
+	}
+}
+
+// pauseBy pauses the game on the host, the remote side sees the name of the {player} who paused it
+func (g *GameDrawer) pauseBy(player *pkg.Player) {
+	if !g.Game.IsLocal() {
+		g.menu.pausedBy = player.Name
+	}
+	g.updateCurrentState(pkg.PauseGame)
+}
+
+// forfeit gives up the match of the local player and it notifies the remote side
+func (g *GameDrawer) forfeit() {
+	side := g.Game.LocalSide()
+	g.send(network.NewMessage(network.Forfeit.String(), side.String()))
+	g.applyForfeit(side)
+}
+
+// applyForfeit ends the match, the player of the {side} gives up and its opponent wins
+func (g *GameDrawer) applyForfeit(side pkg.PlayerSide) {
+	if err := g.Game.CanTransition(pkg.WinGame); err != nil {
+		g.logger.Warn("fail to forfeit the match", "side", side.String(), "err", err)
+		return
+	}
+	g.addMessageWithLevel(fmt.Sprintf("%s forfeits the match", g.Game.Player(side).Name), warning)
+	g.Game.Forfeit(side)
+	g.updateCurrentState(pkg.WinGame)
+}
+
+// syncMenu sends the focused item (and the player who paused the game for the host) to the remote side if it changed
+func (g *GameDrawer) syncMenu(now time.Time) {
+	if !g.menu.dirty || !g.menuOpen() || now.Sub(g.menu.sentAt) < menuSyncDelay {
+		return
+	}
+	g.menu.dirty = false
+	if g.peerWith(network.CapabilityMenu) != nil {
+		g.menu.sentAt = now
+		g.send(network.NewMessage(network.Menu.String(), g.menuState()))
+	}
+}
+
+// menuState builds the [Menu] message of the local player
+func (g *GameDrawer) menuState() network.MenuState {
+	state := network.MenuState{Item: g.menu.item.String()}
+	if g.menu.stats {
+		state.Item = menuStats.String()
+	}
+	if g.Game.IsRemoteServer() {
+		state.PausedBy = g.menu.pausedBy
+	}
+	return state
+}
+
+// drawMenu draws the pause menu (or the stats of the match) over the table
+func (g *GameDrawer) drawMenu(screen *ebiten.Image) {
+	font := g.Game.Screen.Font.Text
+	fontSize := g.Game.Screen.Font.TextSize
+	marginY := float32(fontSize + 12)
+	white := g.Game.Screen.AvailableColors[pkg.ColorText]
+	focus := g.Game.Screen.AvailableColors[pkg.ColorAccent3]
+
+	// the panel grows with the UI scale
+	width, height := 560, max(440, int(marginY)*(len(g.menuItems())+5))
+	x := float32(g.Game.Screen.GameZoneXCenter() - width/2)
+	y := float32(g.Game.Screen.GameZoneYCenter() - height/2)
+	DrawRectangle(screen, width+6, height+6, pkg.Position{X: x - 3, Y: y - 3}, g.Game.Screen.AvailableColors[pkg.ColorAccent1])
+	DrawRectangle(screen, width, height, pkg.Position{X: x, Y: y}, g.Game.Screen.AvailableColors[pkg.ColorPanel])
+
+	title := "PAUSED"
+	if g.menu.pausedBy != "" {
+		title = "PAUSED BY " + strings.ToUpper(g.menu.pausedBy)
+	}
+	x += 30
+	y += 30
+	DrawText(screen, title, font, white, pkg.Position{X: x, Y: y})
+	y += marginY * 1.5
+
+	if g.menu.stats {
+		g.drawMenuStats(screen, x, y)
+		return
+	}
+
+	settings := pkg.CurrentSettings()
+	onOff := map[bool]string{true: "on", false: "off"}
+	for _, item := range g.menuItems() {
+		c, prefix := white, "  "
+		if item == g.menu.item {
+			c, prefix = focus, "> "
+		}
+		value := ""
+		switch item {
+		case menuVolume:
+			value = fmt.Sprintf("%d%%", int(settings.Volume*100+0.5))
+		case menuSound:
+			value = onOff[!settings.Mute]
+		case menuFullscreen:
+			value = onOff[ebiten.IsFullscreen()]
+		case menuEffects:
+			value = onOff[settings.Effects]
+		case menuTrail:
+			value = onOff[settings.HighContrastTrail]
+		case menuAudioCues:
+			value = onOff[settings.AudioCues]
+		}
+		DrawText(screen, fmt.Sprintf("%s%-14s%s", prefix, strings.ToUpper(item.String()), value), font, c, pkg.Position{X: x, Y: y})
+		y += marginY
+	}
+
+	if g.menu.remote != "" {
+		DrawText(screen, fmt.Sprintf("%s is on %s", g.remotePlayer().Name, strings.ToUpper(g.menu.remote)),
+			g.Game.Screen.Font.SmallText, white, pkg.Position{X: x, Y: y + 5})
+	}
+
+	help := "[up/down] item - [left/right] change - [enter] select - [esc] resume"
+	DrawText(screen, help, g.Game.Screen.Font.TinyText, white,
+		pkg.Position{X: x - 15, Y: float32(g.Game.Screen.GameZoneYCenter()+height/2) - float32(g.Game.Screen.Font.TinyTextSize) - 10})
+}
+
+// drawMenuStats draws the score, the sets and the rules of the match from the {x, y} position
+func (g *GameDrawer) drawMenuStats(screen *ebiten.Image, x, y float32) {
+	font := g.Game.Screen.Font.SmallText
+	marginY := float32(g.Game.Screen.Font.SmallTextSize + 10)
+	white := g.Game.Screen.AvailableColors[pkg.ColorText]
+
+	lines := []string{
+		"# SCORE",
+		fmt.Sprintf("%s %d - %d %s", g.Game.PlayerL.Name, g.Game.PlayerL.Score, g.Game.PlayerR.Score, g.Game.PlayerR.Name),
+		"",
+		"# SETS",
+	}
+	for nb, set := range g.Game.Win.Sets {
+		if set.Ended() {
+			lines = append(lines, fmt.Sprintf("n°%d %s %s speed %s hits %d",
+				nb+1, set.PlayerSideWin.String(), set.Duration(), set.SpeedFormat(), set.NbHit))
+		} else {
+			lines = append(lines, fmt.Sprintf("n°%d playing %s speed %0.02f hits %d",
+				nb+1, pkg.FormatDuration(g.Game.Clock.Since(set.StartTime)), set.XSpeed, set.NbHit))
+		}
+	}
+	if len(lines) > 12 {
+		// the last sets only
+		lines = append(lines[:4], lines[len(lines)-8:]...)
+	}
+	lines = append(lines,
+		"",
+		"# RULES",
+		fmt.Sprintf("The first to %d points with %d points", g.Game.Win.SetScore, g.Game.Win.SetGapWScore),
+		fmt.Sprintf("difference or the first to %d points", g.Game.Win.Score))
+
+	for _, line := range lines {
+		DrawText(screen, line, font, white, pkg.Position{X: x, Y: y})
+		y += marginY
+	}
+}
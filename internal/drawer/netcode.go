@@ -0,0 +1,258 @@
+package drawer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joakim-ribier/pong/internal/metrics"
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/internal/network/rollback"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// netcode represents the rollback netcode of a set, both sides exchange their inputs on each frame
+// and each side simulates the whole table (the remote inputs are predicted until they are received)
+type netcode struct {
+	// settings is nil if the host plays without the rollback netcode
+	settings *network.Rollback
+	session  *rollback.Session[frameState]
+
+	// events published by each simulated frame, they are dispatched once the frame is confirmed
+	// (the frames simulated again after a misprediction replace their events)
+	events    map[int][]pkg.Event
+	simulated int
+	published int
+
+	// rollbacks per second for the debug overlay
+	rate      float64
+	sampled   rollback.Stats
+	sampledAt time.Time
+}
+
+// frameState represents the state of the table saved on each frame to roll it back
+type frameState struct {
+	frame      int
+	ball       pkg.Position
+	ballXSpeed float32
+	ballYSpeed float32
+	paddleLY   float32
+	paddleRY   float32
+	setXSpeed  float32
+	setNbHit   int
+	random     []byte
+}
+
+// simulation runs the table of the game frame by frame for the rollback netcode
+type simulation struct {
+	g *GameDrawer
+}
+
+func (s simulation) Save() frameState {
+	state := frameState{
+		frame:      s.g.netcode.simulated,
+		ball:       s.g.Game.Ball.Position,
+		ballXSpeed: s.g.Game.Ball.XSpeed,
+		ballYSpeed: s.g.Game.Ball.YSpeed,
+		paddleLY:   s.g.Game.PlayerL.Paddle.Y,
+		paddleRY:   s.g.Game.PlayerR.Paddle.Y,
+		random:     s.g.Game.Random.State(),
+	}
+	if set := s.g.Game.CurrentSet(); set != nil {
+		state.setXSpeed, state.setNbHit = set.XSpeed, set.NbHit
+	}
+	return state
+}
+
+func (s simulation) Load(state frameState) {
+	s.g.netcode.simulated = state.frame
+	s.g.Game.Ball.Position = state.ball
+	s.g.Game.Ball.XSpeed = state.ballXSpeed
+	s.g.Game.Ball.YSpeed = state.ballYSpeed
+	s.g.Game.PlayerL.Paddle.Y = state.paddleLY
+	s.g.Game.PlayerR.Paddle.Y = state.paddleRY
+	_ = s.g.Game.Random.SetState(state.random)
+	if set := s.g.Game.CurrentSet(); set != nil {
+		set.XSpeed, set.NbHit = state.setXSpeed, state.setNbHit
+	}
+}
+
+func (s simulation) Step(local, remote rollback.Input) {
+	inputs := map[pkg.PlayerSide]rollback.Input{s.g.Game.LocalSide(): local, s.g.Game.LocalSide().Other(): remote}
+	// the events of the frame are held until it is confirmed, a predicted frame can be simulated again
+	events := s.g.Game.Events.Capture(func() {
+		for _, player := range []*pkg.Player{s.g.Game.PlayerL, s.g.Game.PlayerR} {
+			if inputs[player.Side]&rollback.InputUp != 0 {
+				player.Paddle.Y -= player.Paddle.Speed
+			}
+			if inputs[player.Side]&rollback.InputDown != 0 {
+				player.Paddle.Y += player.Paddle.Speed
+			}
+			NewPaddleDrawer(*player).Update(s.g.Game.Screen, false)
+		}
+		s.g.BallDrawer.Update(s.g.Game, s.g.Game.Screen, false)
+	})
+	if len(events) > 0 {
+		s.g.netcode.events[s.g.netcode.simulated] = events
+	} else {
+		delete(s.g.netcode.events, s.g.netcode.simulated)
+	}
+	s.g.netcode.simulated++
+}
+
+// WithRollback plays the hosted matches with the rollback netcode,
+// the inputs of both players are delayed of {inputDelay} frames
+func (g *GameDrawer) WithRollback(inputDelay int) *GameDrawer {
+	g.netcode.settings = &network.Rollback{InputDelay: inputDelay}
+	return g
+}
+
+// startRollback starts the rollback session of a new set if both sides support it
+func (g *GameDrawer) startRollback() {
+	g.netcode.session = nil
+	if g.netcode.settings == nil || g.peerWith(network.CapabilityRollback) == nil {
+		return
+	}
+
+	session, err := rollback.New[frameState](simulation{g: g}, g.netcode.settings.InputDelay)
+	if err != nil {
+		g.logger.Warn("fail to start the rollback netcode", "err", err)
+		return
+	}
+	g.netcode.session = session
+	g.netcode.events, g.netcode.simulated, g.netcode.published = map[int][]pkg.Event{}, 0, -1
+	g.netcode.sampled, g.netcode.sampledAt, g.netcode.rate = rollback.Stats{}, time.Now(), 0
+}
+
+// hookRollback stops the rollback session when the game leaves the set for another state than play or pause
+func (g *GameDrawer) hookRollback() {
+	stop := func(to pkg.State) {
+		if to != pkg.PlayGame && to != pkg.PauseGame {
+			g.stopRollback()
+		}
+	}
+	for _, state := range []pkg.State{pkg.ResumeGame, pkg.PlayGame, pkg.PauseGame} {
+		g.Game.OnExit(state, stop)
+	}
+}
+
+// stopRollback plays the rest of the set without the rollback netcode
+// (e.g. the state of the match is restored from a snapshot)
+func (g *GameDrawer) stopRollback() {
+	g.netcode.session = nil
+}
+
+// advanceRollback sends the local input and it simulates the next frame of the table
+func (g *GameDrawer) advanceRollback() {
+	session := g.netcode.session
+	if session.Frame() == 0 {
+		// both sides start the set with the paddles in the middle of the table
+		for _, player := range []*pkg.Player{g.Game.PlayerL, g.Game.PlayerR} {
+			player.Paddle.Y = float32(g.Game.Screen.GameZoneYCenter()) - float32(player.Paddle.Height/2)
+		}
+	}
+
+	session.AddLocalInput(g.localInput())
+	from, inputs := session.LocalInputs()
+	values := make([]uint8, 0, len(inputs))
+	for _, input := range inputs {
+		values = append(values, uint8(input))
+	}
+	g.send(network.NewMessage(network.Input.String(), network.InputFrames{Frame: from, Inputs: values}))
+
+	advanced := session.Advance()
+	g.sampleRollbacks(time.Now())
+	if !advanced {
+		return
+	}
+	g.publishConfirmed()
+
+	// each side decides if its player lost the ball once the frame is confirmed by both sides
+	if state, ok := session.ConfirmedState(); ok {
+		if lost := g.lostBall(state); lost.PlayerLostBall() {
+			g.updateCurrentState(lost)
+		}
+	}
+}
+
+// publishConfirmed dispatches the events of the frames confirmed by both sides
+func (g *GameDrawer) publishConfirmed() {
+	for f := g.netcode.published + 1; f <= g.netcode.session.Confirmed(); f++ {
+		for _, event := range g.netcode.events[f] {
+			g.Game.Events.Publish(event)
+		}
+		delete(g.netcode.events, f)
+		g.netcode.published = f
+	}
+}
+
+// addRemoteInputs records the inputs of the remote player, it returns false if the inputs are not valid
+func (g *GameDrawer) addRemoteInputs(frames network.InputFrames) bool {
+	if frames.Frame < 0 || len(frames.Inputs) == 0 || len(frames.Inputs) > rollback.Redundancy {
+		return false
+	}
+	for _, value := range frames.Inputs {
+		if !rollback.Input(value).Valid() {
+			return false
+		}
+	}
+
+	if session := g.netcode.session; session != nil {
+		for i, value := range frames.Inputs {
+			session.AddRemoteInput(frames.Frame+i, rollback.Input(value))
+		}
+	}
+	return true
+}
+
+// localInput returns the buttons pressed by the local player
+func (g *GameDrawer) localInput() rollback.Input {
+	var input rollback.Input
+	if g.PlayersDrawer.inputLocked {
+		return input
+	}
+	options := g.localPlayer().Options
+	if ebiten.IsKeyPressed(options.Up) {
+		input |= rollback.InputUp
+	}
+	if ebiten.IsKeyPressed(options.Down) {
+		input |= rollback.InputDown
+	}
+	return input
+}
+
+// lostBall returns the state of the game if the local player lost the ball in the {state} of the table
+func (g *GameDrawer) lostBall(state frameState) pkg.State {
+	switch {
+	case g.Game.LocalSide() == pkg.PlayerLeft && state.ball.X < g.Game.PlayerL.Paddle.X:
+		return pkg.PlayerLLostBall
+	case g.Game.LocalSide() == pkg.PlayerRight && state.ball.X > g.Game.PlayerR.Paddle.X:
+		return pkg.PlayerRLostBall
+	}
+	return g.Game.CurrentState()
+}
+
+// sampleRollbacks computes the number of rollbacks per second
+func (g *GameDrawer) sampleRollbacks(now time.Time) {
+	elapsed := now.Sub(g.netcode.sampledAt)
+	if elapsed < time.Second {
+		return
+	}
+	stats := g.netcode.session.Stats()
+	rollbacks := stats.Rollbacks - g.netcode.sampled.Rollbacks
+	metrics.Rollbacks.With("").Add(float64(rollbacks))
+
+	g.netcode.rate = float64(rollbacks) / elapsed.Seconds()
+	g.netcode.sampled, g.netcode.sampledAt = stats, now
+}
+
+// rollbackDebug returns the debug info of the rollback netcode
+func (g *GameDrawer) rollbackDebug() string {
+	session := g.netcode.session
+	if session == nil {
+		return "\nRollback: off"
+	}
+	stats := session.Stats()
+	return fmt.Sprintf("\nRollback: frame %d, confirmed %d, input delay %d", session.Frame(), session.Confirmed(), session.Delay()) +
+		fmt.Sprintf("\nRollbacks: %d (%0.1f/s), resimulated %d, max depth %d", stats.Rollbacks, g.netcode.rate, stats.Resimulated, stats.MaxDepth)
+}
@@ -0,0 +1,49 @@
+package drawer
+
+import (
+	"testing"
+
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/internal/network/rollback"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+func TestRollbackPublishesConfirmedFrames(t *testing.T) {
+	g := NewDrawerGame(pkg.NewGame(pkg.RemoteServerMode, false), func(network.Message) {}, func() {}, "test")
+	g.Game.StartNewSet()
+	session, err := rollback.New[frameState](simulation{g: g}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.netcode.session = session
+	g.netcode.events, g.netcode.simulated, g.netcode.published = map[int][]pkg.Event{}, 0, -1
+
+	var bounces int
+	pkg.Subscribe(g.Game.Events, func(pkg.WallBounce) { bounces++ })
+
+	// the ball bounces on the bottom wall on the first frame
+	g.Game.Ball.Position = pkg.Position{X: float32(g.Game.Screen.GameZoneXCenter()), Y: g.Game.Screen.YBottom + 16}
+	g.Game.Ball.XSpeed, g.Game.Ball.YSpeed = 0, -5
+
+	for range 3 {
+		g.advanceRollback()
+	}
+	if bounces != 0 {
+		t.Fatalf("got %d bounces published by the predicted frames", bounces)
+	}
+
+	// the misprediction of the first frame simulates the frames again
+	session.AddRemoteInput(0, rollback.InputUp)
+	g.advanceRollback()
+	if bounces != 1 {
+		t.Fatalf("got %d bounces, expected the bounce of the confirmed frame", bounces)
+	}
+
+	for f := 1; f < 4; f++ {
+		session.AddRemoteInput(f, rollback.InputUp)
+	}
+	g.advanceRollback()
+	if bounces != 1 || session.Stats().Rollbacks != 1 {
+		t.Errorf("got %d bounces and %d rollbacks, expected the bounce once", bounces, session.Stats().Rollbacks)
+	}
+}
@@ -1,41 +1,50 @@
 package drawer
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"image/color"
+	"log/slog"
 	"time"
-)
 
-// networkMessageLevel represents type of a log level
-type networkMessageLevel int
+	"github.com/joakim-ribier/pong/pkg"
+)
 
+// channel levels are the {slog} levels used to display messages in the [CHANNEL]
 const (
-	info networkMessageLevel = iota
-	logg
-	warning
+	info    = slog.LevelInfo
+	logg    = slog.LevelDebug
+	warning = slog.LevelWarn
 )
 
 // networkMessage represents a message to display to the user
 type networkMessage struct {
 	dateTime string
 	text     string
-	level    networkMessageLevel
+	level    slog.Level
+	color    color.Color
 }
 
-// asColor maps the {level} field to a {color}
+// asColor maps the {level} field to a {color} (or the colour of the sender of a chat message)
 func (t networkMessage) asColor(colors map[string]color.Color) color.Color {
+	if t.color != nil {
+		return t.color
+	}
 	switch t.level {
 	case info:
-		return colors["#atari3"]
+		return colors[pkg.ColorAccent3]
 	case warning:
-		return colors["#atari1"]
+		return colors[pkg.ColorAccent1]
 	default:
-		return colors["black"]
+		return colors[pkg.ColorLog]
 	}
 }
 
 type readyToPlay struct {
 	ready        bool
-	nbTpsLaps    int
+	since        time.Duration
 	nbSeconds    int
 	nbSecondsMax int
 }
@@ -48,6 +57,12 @@ type networkClient struct {
 	nbPingAttempts    int
 	nbPingMaxAttempts int
 	version           string
+	token             string
+	disconnectedAt    time.Time
+	capabilities      []string
+	guard             *guard
+	// migrated is true for the seat of the former host which handed the match over
+	migrated bool
 }
 
 // newRemoteClient builds a new {networkClient} type
@@ -56,24 +71,87 @@ func newRemoteClient(networkAddr string) *networkClient {
 		networkAddr:       networkAddr,
 		nbPingAttempts:    0,
 		nbPingMaxAttempts: 3,
+		guard:             newGuard(),
 	}
 }
 
+// disconnected returns true if the client does not respond anymore
+// and it waits for it to resume its session
+func (t networkClient) disconnected() bool {
+	return !t.disconnectedAt.IsZero()
+}
+
 // networkData represents data for the network game
 type networkData struct {
 	clients     map[string]*networkClient
 	messages    []networkMessage
 	readyToPlay readyToPlay
+	swap        swapRequests
+
+	// session is the token issued by the server to resume the session (client side)
+	session string
+	// handedOver is the address of the client which took the match over (former host side)
+	handedOver string
+	// promoting is true while the client takes over the port of the former host
+	promoting bool
+	// sessionGraceWindow is the time to wait for a disconnected client before to delete it
+	sessionGraceWindow time.Duration
 }
 
 // newNetworkData builds a new {networkData} type
 func newNetworkData() *networkData {
 	return &networkData{
-		clients:     make(map[string]*networkClient),
-		readyToPlay: readyToPlay{ready: false, nbSeconds: 0, nbTpsLaps: 0, nbSecondsMax: 3},
+		clients:            make(map[string]*networkClient),
+		readyToPlay:        readyToPlay{ready: false, nbSeconds: 0, nbSecondsMax: 3},
+		sessionGraceWindow: 30 * time.Second,
+	}
+}
+
+// findByToken finds the client of the {token} session
+func (d *networkData) findByToken(token string) *networkClient {
+	for _, client := range d.clients {
+		if token != "" && client.token == token {
+			return client
+		}
+	}
+	return nil
+}
+
+// newSessionToken generates a random session token
+func newSessionToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// channelHandler is a {slog.Handler} which displays the records in the [CHANNEL]
+type channelHandler struct {
+	data *networkData
+}
+
+func (h channelHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h channelHandler) Handle(_ context.Context, r slog.Record) error {
+	maxSize := 40
+	if len(h.data.messages) > maxSize {
+		h.data.messages = h.data.messages[len(h.data.messages)-maxSize:]
 	}
+
+	message := networkMessage{dateTime: fmt.Sprintf("[%s]", r.Time.Format("15:04:05")), text: r.Message, level: r.Level}
+	r.Attrs(func(attr slog.Attr) bool {
+		if c, ok := attr.Value.Any().(color.Color); ok && attr.Key == "color" {
+			message.color = c
+		}
+		return true
+	})
+
+	h.data.messages = append(h.data.messages, message)
+	return nil
 }
 
+func (h channelHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h channelHandler) WithGroup(string) slog.Handler      { return h }
+
 // ping computes the time elapsed between ping and pong in ms
 func (t networkClient) ping() time.Duration {
 	if t.lastPong.After(t.lastPing) {
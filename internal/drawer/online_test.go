@@ -0,0 +1,668 @@
+package drawer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+func TestSubscribeAccepted(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+
+	welcome := decodeWelcome(t, client.waitFor(t, network.Subscribe))
+	if welcome.Status != network.Accepted || !slices.Equal(welcome.Capabilities, network.Capabilities) || welcome.Rules == nil {
+		t.Fatalf("unexpected welcome: %+v", welcome)
+	}
+	client.eventually(t, func(g *GameDrawer) bool {
+		return g.hasMessage("Press [space] to start...") && len(g.remoteData.clients) == 1
+	}, "client should register the server after acceptance")
+
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.hasMessage("New subscriber...") && len(g.remoteData.clients) == 1
+	}, "server should register the client")
+}
+
+func TestAnnouncement(t *testing.T) {
+	server, addr := newServerPeer(t)
+	if announcement := server.drawer.Announcement(); announcement.FreeSlots != 1 || announcement.Rules.Score == 0 {
+		t.Fatalf("got %+v, want a free slot and the rules of the match", announcement)
+	}
+
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	// the announcement is published by the game loop, the LAN announcer reads it from its own goroutine
+	server.eventually(t, func(g *GameDrawer) bool {
+		g.publishAnnouncement()
+		return len(g.remoteData.clients) == 1
+	}, "client not subscribed")
+	if announcement := server.drawer.Announcement(); announcement.FreeSlots != 0 {
+		t.Errorf("got %+v, want no free slot", announcement)
+	}
+}
+
+func TestSubscribeRefused(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+
+	other := newClientPeer(t, addr)
+	if welcome := decodeWelcome(t, other.waitFor(t, network.Subscribe)); welcome.Status != network.Refused {
+		t.Fatalf("subscribe status: got %v, want refused", welcome.Status)
+	}
+	other.eventually(t, func(g *GameDrawer) bool {
+		return g.hasMessage("Connection refused...") && g.hasMessage("the game is full") && len(g.remoteData.clients) == 0
+	}, "refused client should not register the server")
+
+	server.eventually(t, func(g *GameDrawer) bool {
+		return len(g.remoteData.clients) == 1
+	}, "server should keep only the first client")
+}
+
+func TestSubscribeRefusedIncompatibleProtocol(t *testing.T) {
+	server, addr := newServerPeer(t)
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(),
+		network.Hello{Protocol: network.ProtocolVersion + 1, Capabilities: network.Capabilities}))
+	welcome := decodeWelcome(t, client.waitFor(t, network.Subscribe))
+	if welcome.Status != network.Refused || welcome.Reason == "" {
+		t.Fatalf("unexpected welcome: %+v", welcome)
+	}
+
+	server.eventually(t, func(g *GameDrawer) bool {
+		return len(g.remoteData.clients) == 0 && g.hasMessage(fmt.Sprintf("%s refused: %s", client.addr(), welcome.Reason))
+	}, "server should refuse the client with the reason")
+}
+
+func TestSubscribeRefusedOutdatedClient(t *testing.T) {
+	server, addr := newServerPeer(t)
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewSimpleMessage(network.Subscribe.String()))
+	if message := client.waitFor(t, network.Subscribe); message.Data.Value != network.Refused {
+		t.Fatalf("subscribe value: got %v, want refused", message.Data.Value)
+	}
+
+	server.eventually(t, func(g *GameDrawer) bool {
+		return len(g.remoteData.clients) == 0 && g.hasMessage(fmt.Sprintf("%s refused: outdated client", client.addr()))
+	}, "server should refuse the outdated client")
+}
+
+func TestPingPong(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	client.eventually(t, func(g *GameDrawer) bool { return len(g.remoteData.clients) == 1 }, "client not subscribed")
+
+	// ping the client as the ticker does
+	server.do(func() {
+		server.drawer.send(network.NewSimpleMessage(network.Ping.String()))
+		server.drawer.HandleNetworkMessage(network.NewSimpleMessage(network.PingAll.String()))
+	})
+	server.waitFor(t, network.Pong)
+	server.eventually(t, func(g *GameDrawer) bool {
+		for _, c := range g.remoteData.clients {
+			return c.version == "test-client" && c.nbPingAttempts == 0 && !c.lastPong.IsZero()
+		}
+		return false
+	}, "server should update the client on pong")
+
+	// the client pings the server after its subscription
+	client.waitFor(t, network.Pong)
+	client.eventually(t, func(g *GameDrawer) bool {
+		for _, c := range g.remoteData.clients {
+			return c.version == "test-server" && !c.lastPong.IsZero()
+		}
+		return false
+	}, "client should update the server on pong")
+}
+
+func TestReady(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+
+	client.do(func() { client.drawer.send(network.NewMessage(network.Ready.String(), true)) })
+	message := server.waitFor(t, network.Ready)
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.remoteData.readyToPlay.ready && g.hasMessage(fmt.Sprintf("%s ready to play", message.NetworkAddr))
+	}, "server should see the client ready")
+
+	client.do(func() { client.drawer.send(network.NewMessage(network.Ready.String(), false)) })
+	server.waitFor(t, network.Ready)
+	server.eventually(t, func(g *GameDrawer) bool {
+		return !g.remoteData.readyToPlay.ready && g.hasMessage(fmt.Sprintf("%s not ready anymore", message.NetworkAddr))
+	}, "server should see the client not ready anymore")
+}
+
+func TestUpdateCurrentState(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	server.eventually(t, func(g *GameDrawer) bool { return len(g.remoteData.clients) == 1 }, "client not subscribed")
+
+	server.do(func() { server.drawer.updateCurrentState(pkg.ResumeGame) })
+	client.waitFor(t, network.UpdateCurrentState)
+	client.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.CurrentState() == pkg.ResumeGame && len(g.Game.Win.Sets) == 1
+	}, "client should start a new set")
+
+	server.do(func() { server.drawer.updateCurrentState(pkg.PauseGame) })
+	client.waitFor(t, network.UpdateCurrentState)
+	client.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.CurrentState() == pkg.PauseGame
+	}, "client should pause the game")
+}
+
+func TestShutdown(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	server.do(func() { server.drawer.updateCurrentState(pkg.ResumeGame) })
+
+	client.conn.Shutdown()
+	server.waitFor(t, network.Shutdown)
+	server.eventually(t, func(g *GameDrawer) bool {
+		return len(g.remoteData.clients) == 0 &&
+			g.Game.CurrentState() == pkg.StartGame &&
+			len(g.Game.Win.Sets) == 0 &&
+			g.hasMessage("Lost connection...")
+	}, "server should delete the client and reset the game")
+}
+
+func TestPingAllDisconnectsAfterThreeMissedPings(t *testing.T) {
+	server, addr := newServerPeer(t)
+	server.do(func() { server.drawer.remoteData.sessionGraceWindow = 0 })
+
+	// a raw client subscribes but never answers to the pings
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	server.waitFor(t, network.Subscribe)
+	server.waitFor(t, network.PingAll) // first ping sent after the subscription
+
+	for i := 0; i < 2; i++ {
+		server.handle(network.NewSimpleMessage(network.PingAll.String()))
+	}
+	server.eventually(t, func(g *GameDrawer) bool {
+		c, ok := g.remoteData.clients[client.addr()]
+		return ok && c.nbPingAttempts == c.nbPingMaxAttempts
+	}, "client should be kept until the max attempts")
+
+	server.handle(network.NewSimpleMessage(network.PingAll.String()))
+	server.eventually(t, func(g *GameDrawer) bool {
+		c, ok := g.remoteData.clients[client.addr()]
+		return ok && c.disconnected() && g.hasMessage(fmt.Sprintf("%s not responding...", client.addr()))
+	}, "client should be disconnected after three missed pings")
+
+	server.handle(network.NewSimpleMessage(network.PingAll.String()))
+	server.eventually(t, func(g *GameDrawer) bool {
+		_, ok := g.remoteData.clients[client.addr()]
+		return !ok && g.hasMessage(fmt.Sprintf("%s disconnected", client.addr()))
+	}, "client should be evicted after the session grace window")
+}
+
+func TestResumeSessionFromNewPort(t *testing.T) {
+	server, addr := newServerPeer(t)
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	token := client.waitFor(t, network.Session).Data.Value.(string)
+
+	// play a few points then lose the client
+	server.do(func() {
+		server.drawer.updateCurrentState(pkg.ResumeGame)
+		server.drawer.updateCurrentState(pkg.PlayerRLostBall)
+		server.drawer.updateCurrentState(pkg.PlayGame)
+		server.drawer.disconnect(server.drawer.remoteData.clients[client.addr()])
+	})
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.CurrentState() == pkg.PauseGame
+	}, "match should be paused during the grace window")
+
+	// the same player comes back from a new UDP port
+	other := newRawClient(t, addr)
+	other.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello(token)))
+	if welcome := decodeWelcome(t, other.waitFor(t, network.Subscribe)); welcome.Status != network.Accepted {
+		t.Fatalf("subscribe status: got %v, want accepted", welcome.Status)
+	}
+	snapshot, err := network.DecodeValue[pkg.Snapshot](other.waitFor(t, network.Resume))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.PlayerL != 1 || snapshot.PlayerR != 0 || len(snapshot.Sets) != 2 || snapshot.State != pkg.PauseGame.String() {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	server.eventually(t, func(g *GameDrawer) bool {
+		_, oldOk := g.remoteData.clients[client.addr()]
+		c, newOk := g.remoteData.clients[other.addr()]
+		return !oldOk && newOk && !c.disconnected() && g.Game.PlayerL.Score == 1
+	}, "server should move the session to the new address")
+}
+
+func TestResumeSessionOnClient(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Session)
+
+	server.do(func() {
+		server.drawer.updateCurrentState(pkg.ResumeGame)
+		server.drawer.updateCurrentState(pkg.PlayerLLostBall)
+	})
+	client.waitFor(t, network.UpdateCurrentState)
+
+	// the client lost the server and tries to resume its session on the next ping
+	client.do(func() {
+		for _, c := range client.drawer.remoteData.clients {
+			client.drawer.disconnect(c)
+		}
+		client.drawer.HandleNetworkMessage(network.NewSimpleMessage(network.PingAll.String()))
+	})
+	client.waitFor(t, network.Resume)
+	client.eventually(t, func(g *GameDrawer) bool {
+		for _, c := range g.remoteData.clients {
+			return !c.disconnected() && g.Game.PlayerR.Score == 1 && len(g.Game.Win.Sets) == 2
+		}
+		return false
+	}, "client should resume the match with the score and the sets")
+}
+
+func TestSecuredRoom(t *testing.T) {
+	server, addr := newSecuredServerPeer(t, "s3cr3t")
+	client := newSecuredClientPeer(t, addr, "s3cr3t")
+
+	if welcome := decodeWelcome(t, client.waitFor(t, network.Subscribe)); welcome.Status != network.Accepted {
+		t.Fatalf("subscribe status: got %v, want accepted", welcome.Status)
+	}
+	server.eventually(t, func(g *GameDrawer) bool {
+		return len(g.remoteData.clients) == 1
+	}, "server should register the client of the secured room")
+
+	client.do(func() { client.drawer.send(network.NewMessage(network.Ready.String(), true)) })
+	server.waitFor(t, network.Ready)
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.remoteData.readyToPlay.ready
+	}, "server should read the sealed messages of the client")
+}
+
+func TestSecuredRoomRejectsUnauthenticatedDatagrams(t *testing.T) {
+	server, addr := newSecuredServerPeer(t, "s3cr3t")
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	client.send(t, network.NewMessage(network.UpdateCurrentState.String(), pkg.PlayGame.String()))
+	client.silent(t, 500*time.Millisecond)
+
+	server.eventually(t, func(g *GameDrawer) bool {
+		return len(g.remoteData.clients) == 0 && g.Game.CurrentState() == pkg.StartGame
+	}, "server should ignore the plaintext datagrams")
+}
+
+func TestIllegalTransitionIsRejected(t *testing.T) {
+	server, addr := newServerPeer(t)
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	client.waitFor(t, network.Subscribe)
+
+	// the client can not score a point on its own outside the game
+	client.send(t, network.NewMessage(network.UpdateCurrentState.String(), pkg.PlayerRLostBall.String()))
+	server.waitFor(t, network.UpdateCurrentState)
+	server.eventually(t, func(g *GameDrawer) bool {
+		c, ok := g.remoteData.clients[client.addr()]
+		return ok && c.guard.violations == 1 && g.Game.CurrentState() == pkg.StartGame && g.Game.PlayerL.Score == 0
+	}, "server should reject the illegal transition")
+
+	// the state sent again is not a transition
+	client.send(t, network.NewMessage(network.UpdateCurrentState.String(), pkg.StartGame.String()))
+	server.waitFor(t, network.UpdateCurrentState)
+	server.eventually(t, func(g *GameDrawer) bool {
+		c, ok := g.remoteData.clients[client.addr()]
+		return ok && c.guard.violations == 1 && g.Game.CurrentState() == pkg.StartGame
+	}, "server should ignore the same state")
+}
+
+func TestKickAfterTooManyViolations(t *testing.T) {
+	server, addr := newServerPeer(t)
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	client.waitFor(t, network.Subscribe)
+
+	for i := 0; i < maxViolations; i++ {
+		client.send(t, network.NewMessage(network.UpdatePaddleY.String(), 10000))
+	}
+	client.waitFor(t, network.Shutdown)
+	server.eventually(t, func(g *GameDrawer) bool {
+		_, ok := g.remoteData.clients[client.addr()]
+		return !ok && slices.ContainsFunc(g.remoteData.messages, func(m networkMessage) bool {
+			return strings.HasPrefix(m.text, fmt.Sprintf("%s kicked: invalid paddle position", client.addr()))
+		})
+	}, "server should kick the cheater")
+}
+
+func TestChat(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	server.eventually(t, func(g *GameDrawer) bool { return len(g.remoteData.clients) == 1 }, "client not subscribed")
+
+	client.do(func() { client.drawer.sendChat("nice shot") })
+	server.waitFor(t, network.Chat)
+	server.eventually(t, func(g *GameDrawer) bool {
+		for _, msg := range g.remoteData.messages {
+			if msg.text == "Player R: nice shot" {
+				return colorToHex(msg.color) == colorToHex(g.Game.Screen.AvailableColors[pkg.ColorTitle])
+			}
+		}
+		return false
+	}, "server should display the chat message with the name and the colour of the sender")
+
+	// the sender is rate limited before to spam the server
+	client.do(func() {
+		for i := 0; i < 5; i++ {
+			client.drawer.sendChat("spam")
+		}
+	})
+	client.eventually(t, func(g *GameDrawer) bool { return g.hasMessage("Slow down...") }, "client should be rate limited")
+}
+
+func TestLobby(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	server.waitFor(t, network.Lobby)
+
+	client.do(func() {
+		client.drawer.Game.PlayerR.Name = "Alice"
+		client.drawer.Game.PlayerR.Options.Color = client.drawer.Game.Screen.AvailableColors[pkg.ColorTitle]
+		client.drawer.updateLocalPlayer()
+		client.drawer.syncLobby(time.Now())
+	})
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.PlayerR.Name == "Alice" &&
+			colorToHex(g.PlayersDrawer.PlayerRight.Options.Color) == colorToHex(g.Game.Screen.AvailableColors[pkg.ColorTitle])
+	}, "server should display the profile of the client")
+
+	// the client is not ready anymore when the host changes the rules
+	client.do(func() { client.drawer.remoteData.readyToPlay.ready = true })
+	server.do(func() {
+		server.drawer.lobby.field = lobbyScore
+		server.drawer.changeLobbyField(1)
+		server.drawer.syncLobby(time.Now())
+	})
+	client.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.Win.Score == 12 && !g.remoteData.readyToPlay.ready && g.hasMessage("The host changed the rules (12/3+2)")
+	}, "client should apply the rules of the host")
+}
+
+func TestLobbyPalette(t *testing.T) {
+	server, addr := newServerPeer(t)
+	var colorR string
+	server.do(func() {
+		server.drawer.Game.Screen.Palette = "deuteranopia"
+		colorR = colorToHex(server.drawer.Game.PlayerR.Options.Color)
+	})
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	server.waitFor(t, network.Lobby)
+
+	client.do(func() {
+		client.drawer.Game.PlayerR.Name = "Alice"
+		client.drawer.Game.PlayerR.Options.Color = client.drawer.Game.Screen.AvailableColors[pkg.ColorTitle]
+		client.drawer.updateLocalPlayer()
+		client.drawer.syncLobby(time.Now())
+	})
+	// the colour-blind palette keeps the colour of the opponent
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.PlayerR.Name == "Alice" && colorToHex(g.PlayersDrawer.PlayerRight.Options.Color) == colorR
+	}, "server should keep the colours of its palette")
+}
+
+func TestLobbyRulesBelongToTheHost(t *testing.T) {
+	server, addr := newServerPeer(t)
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	client.waitFor(t, network.Subscribe)
+
+	client.send(t, network.NewMessage(network.Lobby.String(), network.LobbyState{
+		Profile: network.Profile{Name: "Mallory", Color: "#ffffff"},
+		Rules:   &network.Rules{Score: 1, SetScore: 1, SetGapWScore: 1}}))
+	server.waitFor(t, network.Lobby)
+	server.eventually(t, func(g *GameDrawer) bool {
+		c, ok := g.remoteData.clients[client.addr()]
+		return ok && c.guard.violations == 1 && g.Game.Win.Score == 11 && g.Game.PlayerR.Name == "Player R"
+	}, "server should reject the rules of the client")
+}
+
+func TestSwapSides(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Swap)
+
+	client.do(func() { client.drawer.requestSwap() })
+	server.waitFor(t, network.Swap)
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.remoteData.swap.remote && g.hasMessage("Player R wants to swap the sides, press [F5] to accept")
+	}, "server should receive the request of the client")
+
+	// the host accepts the request, each player keeps its name on the other side
+	server.do(func() { server.drawer.requestSwap() })
+	client.waitFor(t, network.Swap)
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.HostSide == pkg.PlayerRight && g.Game.PlayerR.Name == "Player L" && g.Game.PlayerL.Name == "Player R"
+	}, "server should play on the right")
+	client.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.LocalSide() == pkg.PlayerLeft && g.Game.PlayerL.Name == "Player R" && g.hasMessage("Sides swapped, you play on the left")
+	}, "client should play on the left")
+}
+
+func TestPauseRequest(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	server.eventually(t, func(g *GameDrawer) bool { return len(g.remoteData.clients) == 1 }, "client not subscribed")
+
+	server.do(func() {
+		server.drawer.updateCurrentState(pkg.ResumeGame)
+		server.drawer.updateCurrentState(pkg.PlayGame)
+	})
+	client.eventually(t, func(g *GameDrawer) bool { return g.Game.CurrentState() == pkg.PlayGame }, "client should play")
+
+	client.do(func() { client.drawer.requestPause(true) })
+	server.waitFor(t, network.Pause)
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.CurrentState() == pkg.PauseGame && g.hasMessage("Player R paused the game")
+	}, "server should pause the game on request")
+	client.eventually(t, func(g *GameDrawer) bool { return g.Game.CurrentState() == pkg.PauseGame }, "client should receive the acknowledgment")
+
+	client.do(func() { client.drawer.requestPause(false) })
+	client.eventually(t, func(g *GameDrawer) bool { return g.Game.CurrentState() == pkg.PlayGame }, "client should resume the game")
+}
+
+func TestPauseMenuAndForfeit(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	client.waitFor(t, network.Subscribe)
+	server.eventually(t, func(g *GameDrawer) bool { return len(g.remoteData.clients) == 1 }, "client not subscribed")
+
+	server.do(func() {
+		server.drawer.updateCurrentState(pkg.ResumeGame)
+		server.drawer.updateCurrentState(pkg.PlayGame)
+		server.drawer.pause(true)
+		server.drawer.syncMenu(time.Now())
+	})
+	client.waitFor(t, network.Menu)
+	client.eventually(t, func(g *GameDrawer) bool {
+		return g.menuOpen() && g.menu.pausedBy == "Player L" && g.menu.remote == menuResume.String()
+	}, "client should see who paused the game")
+
+	client.do(func() {
+		client.drawer.focusMenuItem(menuStats)
+		client.drawer.syncMenu(time.Now())
+	})
+	server.waitFor(t, network.Menu)
+	server.eventually(t, func(g *GameDrawer) bool { return g.menu.remote == menuStats.String() }, "server should see the menu of the client")
+
+	client.do(func() { client.drawer.forfeit() })
+	server.waitFor(t, network.Forfeit)
+	server.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.CurrentState() == pkg.WinGame && g.Game.Winner() == g.Game.PlayerL && g.hasMessage("Player R forfeits the match")
+	}, "server should win the match")
+	client.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.CurrentState() == pkg.WinGame && g.Game.Winner() == g.Game.PlayerL
+	}, "client should lose the match")
+}
+
+func TestForfeitBelongsToEachPlayer(t *testing.T) {
+	server, addr := newServerPeer(t)
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	client.waitFor(t, network.Subscribe)
+
+	client.send(t, network.NewMessage(network.Menu.String(), network.MenuState{PausedBy: "Mallory", Item: "quit"}))
+	server.waitFor(t, network.Menu)
+	// the client can not forfeit the match of the host
+	client.send(t, network.NewMessage(network.Forfeit.String(), pkg.PlayerLeft.String()))
+	server.waitFor(t, network.Forfeit)
+	server.eventually(t, func(g *GameDrawer) bool {
+		c, ok := g.remoteData.clients[client.addr()]
+		return ok && c.guard.violations == 2 && g.menu.pausedBy == "" && g.Game.Winner() == nil
+	}, "server should reject the pause and the forfeit of the host by the client")
+}
+
+func TestHostMigration(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	promoted := client.withPromotion(t)
+	client.waitFor(t, network.Subscribe)
+	server.eventually(t, func(g *GameDrawer) bool { return len(g.remoteData.clients) == 1 }, "client not subscribed")
+
+	server.do(func() {
+		server.drawer.updateCurrentState(pkg.ResumeGame)
+		server.drawer.updateCurrentState(pkg.PlayGame)
+		server.drawer.Game.PlayerL.Score, server.drawer.Game.PlayerR.Score = 2, 1
+	})
+
+	// the host quits gracefully during the match
+	server.do(func() {
+		server.drawer.migrate()
+		server.drawer.shutdown()
+	})
+
+	var newAddr string
+	select {
+	case newAddr = <-promoted:
+	case <-time.After(waitTimeout):
+		t.Fatal("client should take over the host")
+	}
+	client.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.IsRemoteServer() && g.Game.HostSide == pkg.PlayerRight &&
+			g.Game.PlayerL.Score == 2 && g.Game.CurrentState() == pkg.PauseGame &&
+			g.hasMessage("Waiting for Player L to join back...")
+	}, "client should host the paused match")
+
+	// a stranger can not take the seat of the former host
+	stranger := newRawClient(t, newAddr)
+	stranger.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	if welcome := decodeWelcome(t, stranger.waitFor(t, network.Subscribe)); welcome.Status == network.Accepted {
+		t.Fatal("stranger should be refused")
+	}
+
+	// the former host joins back the match on the other side with the token of its seat
+	var token string
+	server.do(func() { _, token = server.drawer.HandedOver() })
+	host := newSessionClientPeer(t, newAddr, "", token)
+	host.waitFor(t, network.Resume)
+	host.eventually(t, func(g *GameDrawer) bool {
+		return g.Game.LocalSide() == pkg.PlayerLeft && g.Game.PlayerL.Score == 2 && g.Game.PlayerR.Score == 1 &&
+			g.Game.CurrentState() == pkg.PauseGame
+	}, "former host should resume the match")
+	client.eventually(t, func(g *GameDrawer) bool {
+		client := g.remoteData.findByToken(token)
+		return len(g.remoteData.clients) == 1 && client != nil && !client.migrated && g.peerWith(network.CapabilityRoles) != nil
+	}, "new host should register the former host")
+}
+
+func TestRollbackNetcode(t *testing.T) {
+	server, addr := newServerPeer(t)
+	server.do(func() { server.drawer.WithRollback(2) })
+	client := newClientPeer(t, addr)
+	if welcome := decodeWelcome(t, client.waitFor(t, network.Subscribe)); welcome.Rollback == nil || welcome.Rollback.InputDelay != 2 {
+		t.Fatalf("got %+v, want the rollback settings of the host", welcome.Rollback)
+	}
+	server.eventually(t, func(g *GameDrawer) bool { return len(g.remoteData.clients) == 1 }, "client not subscribed")
+
+	server.do(func() {
+		server.drawer.updateCurrentState(pkg.ResumeGame)
+		server.drawer.updateCurrentState(pkg.PlayGame)
+	})
+	client.eventually(t, func(g *GameDrawer) bool {
+		if g.Game.CurrentState() == pkg.ResumeGame {
+			g.updateCurrentState(pkg.PlayGame)
+		}
+		return g.Game.CurrentState() == pkg.PlayGame && g.netcode.session != nil
+	}, "client should play with the rollback netcode")
+
+	// both sides exchange their inputs on each frame and confirm the frames of the other side
+	confirmed := func(g *GameDrawer) bool {
+		g.advanceRollback()
+		return g.netcode.session.Confirmed() >= 10
+	}
+	for deadline := time.Now().Add(waitTimeout); ; time.Sleep(5 * time.Millisecond) {
+		ok := [2]bool{}
+		server.do(func() { ok[0] = confirmed(server.drawer) })
+		client.do(func() { ok[1] = confirmed(client.drawer) })
+		if ok[0] && ok[1] {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the frames should be confirmed by both sides")
+		}
+	}
+}
+
+func TestRollbackInvalidInputs(t *testing.T) {
+	server, addr := newServerPeer(t)
+
+	client := newRawClient(t, addr)
+	client.send(t, network.NewMessage(network.Subscribe.String(), network.NewHello("")))
+	client.waitFor(t, network.Subscribe)
+
+	client.send(t, network.NewMessage(network.Input.String(), network.InputFrames{Frame: 0, Inputs: []uint8{255}}))
+	server.waitFor(t, network.Input)
+	server.eventually(t, func(g *GameDrawer) bool {
+		c, ok := g.remoteData.clients[client.addr()]
+		return ok && c.guard.violations == 1
+	}, "server should reject the invalid inputs")
+}
+
+func TestSeedSharedByTheHost(t *testing.T) {
+	server, addr := newServerPeer(t)
+	client := newClientPeer(t, addr)
+	welcome := decodeWelcome(t, client.waitFor(t, network.Subscribe))
+	server.eventually(t, func(g *GameDrawer) bool { return len(g.remoteData.clients) == 1 }, "client not subscribed")
+
+	var seed uint64
+	server.do(func() { seed = server.drawer.Game.Random.Seed() })
+	if welcome.Seed != seed {
+		t.Fatalf("got seed %d, want the seed of the host %d", welcome.Seed, seed)
+	}
+	client.eventually(t, func(g *GameDrawer) bool { return g.Game.Random.Seed() == seed }, "client should play with the seed of the host")
+
+	// the host chooses a new seed for the next match
+	server.do(func() {
+		server.drawer.updateCurrentState(pkg.StartGame)
+		seed = server.drawer.Game.Random.Seed()
+	})
+	client.waitFor(t, network.Seed)
+	client.eventually(t, func(g *GameDrawer) bool { return g.Game.Random.Seed() == seed }, "client should receive the seed of the next match")
+}
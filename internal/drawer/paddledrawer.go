@@ -33,10 +33,12 @@ func (p *PaddleDrawer) Draw(screen *ebiten.Image) {
 	screen.DrawImage(p.paddle.Image, pOpts)
 }
 
-func (p *PaddleDrawer) Update(screen pkg.Screen, updateY bool) {
-	borderMarginX := float32(25)
-	borderMarginY := float32(15)
+const (
+	borderMarginX = float32(25)
+	borderMarginY = float32(15)
+)
 
+func (p *PaddleDrawer) Update(screen pkg.Screen, updateY bool) {
 	if p.side == pkg.PlayerLeft {
 		p.paddle.X = screen.XLeft + borderMarginX
 	} else if p.side == pkg.PlayerRight {
@@ -47,13 +49,19 @@ func (p *PaddleDrawer) Update(screen pkg.Screen, updateY bool) {
 		p.updateY()
 	}
 
-	if p.paddle.Y <= float32(screen.YBottom)+borderMarginY {
-		p.paddle.Y = screen.YBottom + borderMarginY
-	} else if p.paddle.Y+float32(p.paddle.Height) >= float32(screen.YTop)-borderMarginY {
-		p.paddle.Y = screen.YTop - float32(p.paddle.Height) - borderMarginY
+	minY, maxY := paddleBounds(screen, p.paddle)
+	if p.paddle.Y <= minY {
+		p.paddle.Y = minY
+	} else if p.paddle.Y >= maxY {
+		p.paddle.Y = maxY
 	}
 }
 
+// paddleBounds returns the min and max Y positions of the {paddle} on the table
+func paddleBounds(screen pkg.Screen, paddle *pkg.Paddle) (float32, float32) {
+	return screen.YBottom + borderMarginY, screen.YTop - float32(paddle.Height) - borderMarginY
+}
+
 func (p *PaddleDrawer) updateY() {
 	if inpututil.IsKeyJustPressed(p.options.Up) {
 		p.paddle.CurrentPressed = p.options.Up
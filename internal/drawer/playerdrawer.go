@@ -6,25 +6,32 @@ import (
 )
 
 type PlayersDrawer struct {
-	game        pkg.Game
+	game        *pkg.Game
 	PlayerLeft  pkg.Player
 	PlayerRight pkg.Player
+
+	// inputLocked ignores the keyboard (e.g. while the player types in the chat)
+	inputLocked bool
+	// bot moves the paddle of its side instead of the keyboard
+	bot *pkg.Bot
 }
 
-func NewPlayerDrawer(game pkg.Game) *PlayersDrawer {
+func NewPlayerDrawer(game *pkg.Game) *PlayersDrawer {
 	return &PlayersDrawer{
 		game:        game,
 		PlayerLeft:  *game.PlayerL,
 		PlayerRight: *game.PlayerR}
 }
 
-func (p *PlayersDrawer) UpdatePaddleY(y interface{}) {
-	// .(float64) => default setting of the encoding/json decoder when unmarshaling JSON numbers
-	if p.game.IsRemoteClient() {
-		p.PlayerLeft.UpdatePaddleY <- float32(y.(float64))
+// UpdatePaddleY updates the paddle of the remote player
+func (p *PlayersDrawer) UpdatePaddleY(y float32) {
+	if p.game.IsLocal() {
+		return
 	}
-	if p.game.IsRemoteServer() {
-		p.PlayerRight.UpdatePaddleY <- float32(y.(float64))
+	if p.game.LocalSide() == pkg.PlayerRight {
+		p.PlayerLeft.UpdatePaddleY <- y
+	} else {
+		p.PlayerRight.UpdatePaddleY <- y
 	}
 }
 
@@ -42,22 +49,24 @@ func (p *PlayersDrawer) UpdateRight(screen pkg.Screen) pkg.State {
 }
 
 func (p *PlayersDrawer) update(player pkg.Player, screen pkg.Screen) pkg.State {
-	NewPaddleDrawer(player).Update(screen,
-		p.game.GameMode == pkg.LocalMode ||
-			(player.Side == pkg.PlayerLeft && p.game.IsRemoteServer()) ||
-			(player.Side == pkg.PlayerRight && p.game.IsRemoteClient()))
+	// each side moves its own paddle and it decides if its player lost the ball
+	owner := p.game.IsLocal() || player.Side == p.game.LocalSide()
+
+	if p.bot != nil && player.Side == p.bot.Side {
+		player.Paddle.Y += float32(p.bot.Move(*player.Paddle, *p.game.Ball, screen)) * player.Paddle.Speed
+		NewPaddleDrawer(player).Update(screen, false)
+	} else {
+		NewPaddleDrawer(player).Update(screen, !p.inputLocked && owner)
+	}
 
-	if p.game.IsRemoteServer() || p.game.IsLocal() {
+	if owner {
 		if player.Side == pkg.PlayerLeft && p.game.Ball.X < player.Paddle.X {
 			return pkg.PlayerLLostBall
 		}
-	}
-
-	if p.game.IsRemoteClient() || p.game.IsLocal() {
 		if player.Side == pkg.PlayerRight && p.game.Ball.X > player.Paddle.X {
 			return pkg.PlayerRLostBall
 		}
 	}
 
-	return p.game.CurrentState
+	return p.game.CurrentState()
 }
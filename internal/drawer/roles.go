@@ -0,0 +1,160 @@
+package drawer
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// swapRequests represents the pending requests to swap the sides between two games
+type swapRequests struct {
+	local  bool
+	remote bool
+}
+
+// WithPromotion lets the client take over the host when the server quits during a match,
+// {promote} listens on the port of the former host in background and it calls {done} from any goroutine,
+// the client becomes the host on the next {Update}
+func (g *GameDrawer) WithPromotion(promote func(port int, done func(err error))) *GameDrawer {
+	g.promote = promote
+	return g
+}
+
+// HandedOver returns the address of the client which took the match over
+// and the session token to join it back (empty if the match was not handed over)
+func (g *GameDrawer) HandedOver() (string, string) {
+	return g.remoteData.handedOver, g.remoteData.session
+}
+
+// peerWith returns the remote side which supports the {capability}
+func (g *GameDrawer) peerWith(capability string) *networkClient {
+	for _, client := range g.remoteData.clients {
+		if !client.disconnected() && slices.Contains(client.capabilities, capability) {
+			return client
+		}
+	}
+	return nil
+}
+
+// requestSwap requests to swap the sides with the remote side or it accepts its request
+func (g *GameDrawer) requestSwap() {
+	if g.peerWith(network.CapabilityRoles) == nil {
+		g.addMessageWithLevel("The remote side can not swap the sides", warning)
+		return
+	}
+
+	if g.remoteData.swap.remote && g.Game.IsRemoteServer() {
+		g.swapSides()
+		g.send(network.NewMessage(network.Swap.String(), g.Game.HostSide.String()))
+		return
+	}
+
+	// the request of the client accepts the pending request of the host
+	g.remoteData.swap.local = true
+	g.send(network.NewMessage(network.Swap.String(), network.SwapRequest))
+	if !g.remoteData.swap.remote {
+		g.addMessageWithLevel("Swap request sent...", info)
+	}
+}
+
+// swapSides swaps the sides of the players, each player keeps its name and its colour
+func (g *GameDrawer) swapSides() {
+	playerL, playerR := g.Game.PlayerL, g.Game.PlayerR
+	playerL.Name, playerR.Name = playerR.Name, playerL.Name
+	playerL.Options.Color, playerR.Options.Color = playerR.Options.Color, playerL.Options.Color
+	playerL.Paddle.CurrentPressed, playerR.Paddle.CurrentPressed = -1, -1
+	g.updatePlayerCopies(playerL)
+	g.updatePlayerCopies(playerR)
+
+	g.Game.HostSide = g.Game.HostSide.Other()
+	g.remoteData.swap = swapRequests{}
+	g.remoteData.readyToPlay.ready = false
+	for _, client := range g.remoteData.clients {
+		client.guard.resetPaddle()
+	}
+
+	side := map[pkg.PlayerSide]string{pkg.PlayerLeft: "left", pkg.PlayerRight: "right"}[g.Game.LocalSide()]
+	g.addMessageWithLevel(fmt.Sprintf("Sides swapped, you play on the %s", side), info)
+}
+
+// requestPause requests the host to pause (or to resume) the game
+func (g *GameDrawer) requestPause(pause bool) {
+	if g.peerWith(network.CapabilityRoles) == nil {
+		return
+	}
+	g.send(network.NewMessage(network.Pause.String(), pause))
+	if pause {
+		g.addMessageWithLevel("Pause requested...", logg)
+	} else {
+		g.addMessageWithLevel("Resume requested...", logg)
+	}
+}
+
+// migrate hands the match over to the client when the host quits during a match
+func (g *GameDrawer) migrate() {
+	client := g.peerWith(network.CapabilityRoles)
+	if !g.Game.IsRemoteServer() || client == nil ||
+		!slices.Contains([]pkg.State{pkg.PlayGame, pkg.ResumeGame, pkg.PauseGame}, g.Game.CurrentState()) {
+		return
+	}
+
+	if g.Game.CurrentState() != pkg.PauseGame {
+		g.updateCurrentState(pkg.PauseGame)
+	}
+	g.send(network.NewMessage(network.Resume.String(), g.Game.Snapshot()).WithAddr(client.networkAddr))
+	// the former host keeps the token of its seat to join back the match
+	g.remoteData.session = newSessionToken()
+	g.remoteData.handedOver = client.networkAddr
+	g.send(network.NewMessage(network.Migrate.String(), g.remoteData.session).WithAddr(client.networkAddr))
+	g.logger.Info("hand the match over to the client", "addr", client.networkAddr)
+}
+
+// takeOver promotes the client to the host of the match when the {host} quits during the match,
+// the former host joins back the match on the same port with the {token} of its seat
+func (g *GameDrawer) takeOver(host *networkClient, token string) {
+	if g.promote == nil || g.remoteData.promoting {
+		return
+	}
+
+	_, value, err := net.SplitHostPort(host.networkAddr)
+	port, _ := strconv.Atoi(value)
+	if err != nil {
+		g.logger.Warn("fail to take over the host", "addr", host.networkAddr, "err", err)
+		g.addMessageWithLevel("Fail to take over the host...", warning)
+		return
+	}
+
+	// the game is paused while the former host releases its port
+	if g.Game.CurrentState() == pkg.PlayGame || g.Game.CurrentState() == pkg.ResumeGame {
+		g.updateCurrentState(pkg.PauseGame)
+	}
+	g.remoteData.promoting = true
+	g.promote(port, func(err error) { g.inbox.post(func() { g.promoted(host, token, port, err) }) })
+}
+
+// promoted applies the role of host once the client listens on the {port} port of the former {host} (or failed to),
+// it runs on the ebiten goroutine which reads the role of the game
+func (g *GameDrawer) promoted(host *networkClient, token string, port int, err error) {
+	g.remoteData.promoting = false
+	if err != nil {
+		g.logger.Warn("fail to take over the host", "addr", host.networkAddr, "err", err)
+		g.addMessageWithLevel("Fail to take over the host...", warning)
+		return
+	}
+
+	g.Game.HostSide = g.Game.LocalSide()
+	g.Game.GameMode = pkg.RemoteServerMode
+
+	// the seat of the former host waits for its token without time limit
+	host.migrated = true
+	host.token = token
+	host.disconnectedAt = time.Now()
+
+	g.addMessageWithLevel(fmt.Sprintf("The host left, you host the match on port %d", port), info)
+	g.addMessageWithLevel(fmt.Sprintf("Waiting for %s to join back...", g.remotePlayer().Name), info)
+}
@@ -50,6 +50,14 @@ func DrawImage(screen *ebiten.Image, img *ebiten.Image, position pkg.Position) {
 	screen.DrawImage(img, options)
 }
 
+// DrawImageWithColor draws the {img} on the {screen} at the specific {position} tinted by the {color}
+func DrawImageWithColor(screen *ebiten.Image, img *ebiten.Image, position pkg.Position, color color.Color) {
+	options := &ebiten.DrawImageOptions{}
+	options.GeoM.Translate(float64(position.X), float64(position.Y))
+	options.ColorScale.ScaleWithColor(color)
+	screen.DrawImage(img, options)
+}
+
 // GetSize compute the size of the {text} field
 func GetSize(text string, fontSize int) int {
 	return len(text) * fontSize
@@ -0,0 +1,206 @@
+package lan
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/pong/internal/drawer"
+	"github.com/joakim-ribier/pong/internal/game/online"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/internal/network/discovery"
+	"github.com/joakim-ribier/pong/internal/network/netsim"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+const (
+	discoverTimeout  = time.Second
+	discoverInterval = 3 * time.Second
+)
+
+var logger = logging.For(logging.Discovery)
+
+// Browser represents the "Join LAN game" screen which lists the servers discovered
+// on the LAN, once a server is picked it runs the online game as a client
+type Browser struct {
+	screen     pkg.Screen
+	debug      bool
+	version    string
+	secret     string
+	conditions netsim.Conditions
+
+	mu           sync.Mutex
+	servers      []discovery.Server
+	searching    bool
+	lastSearchAt time.Time
+
+	selected int
+	message  string
+
+	joined *online.OnlinePGame
+
+	letterbox drawer.Letterbox
+}
+
+// NewBrowser builds a new {Browser} type, the {secret} is used to join the secured rooms
+func NewBrowser(debug bool, version, secret string, conditions netsim.Conditions) *Browser {
+	return &Browser{
+		screen:     pkg.NewThemedScreen(pkg.RemoteClientMode),
+		debug:      debug,
+		version:    version,
+		secret:     secret,
+		conditions: conditions,
+	}
+}
+
+// Title returns the console title
+func (b *Browser) Title() string {
+	return fmt.Sprintf("%s (Join LAN game)", pkg.GameTitle)
+}
+
+// Size returns the size of the window
+func (b *Browser) Size() (int, int) {
+	return b.screen.Width, b.screen.Height
+}
+
+func (b *Browser) Update() error {
+	if b.joined != nil {
+		return b.joined.Drawer().Update()
+	}
+	drawer.UpdateFullscreen()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.searching && time.Since(b.lastSearchAt) > discoverInterval {
+		b.searching = true
+		go b.discover()
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		b.selected = max(b.selected-1, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		b.selected = min(b.selected+1, max(len(b.servers)-1, 0))
+	case inpututil.IsKeyJustPressed(ebiten.KeyR):
+		b.lastSearchAt = time.Time{}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if b.selected < len(b.servers) {
+			b.join(b.servers[b.selected])
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		return drawer.ErrMainMenu
+	}
+
+	return nil
+}
+
+// Close shuts the joined game down when the player goes back to the main menu
+func (b *Browser) Close() {
+	if b.joined != nil {
+		b.joined.Close()
+	}
+}
+
+// discover refreshes the list of the servers discovered on the LAN
+func (b *Browser) discover() {
+	servers, err := discovery.Discover(discovery.DefaultPort, discoverTimeout)
+	if err != nil {
+		logger.Warn("fail to discover the servers", "err", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.servers = servers
+	b.selected = min(b.selected, max(len(servers)-1, 0))
+	b.searching = false
+	b.lastSearchAt = time.Now()
+}
+
+// join connects the client to the {server}
+func (b *Browser) join(server discovery.Server) {
+	if server.Protocol != network.ProtocolVersion {
+		b.message = fmt.Sprintf("%s is incompatible (protocol v%d)...", server.Name, server.Protocol)
+		return
+	}
+	if server.Secured && b.secret == "" {
+		b.message = fmt.Sprintf("%s is secured, restart with the --secret option...", server.Name)
+		return
+	}
+	if server.FreeSlots == 0 {
+		b.message = fmt.Sprintf("%s is full...", server.Name)
+		return
+	}
+
+	pg, err := online.NewPGame(b.debug, pkg.RemoteClientMode, server.Addr, b.version, b.secret, "", b.conditions)
+	if err != nil {
+		logger.Error("fail to join the server", "addr", server.Addr, "err", err)
+		b.message = fmt.Sprintf("Fail to join %s...", server.Addr)
+		return
+	}
+
+	logger.Info("join the server", "name", server.Name, "addr", server.Addr)
+	b.joined = pg
+	ebiten.SetWindowTitle(pg.Title())
+}
+
+func (b *Browser) Draw(screen *ebiten.Image) {
+	if b.joined != nil {
+		b.joined.Drawer().Draw(screen)
+		return
+	}
+
+	b.letterbox.Draw(screen, b.screen, b.draw)
+}
+
+// draw draws the list of the servers on the {screen} in the logical coordinates
+func (b *Browser) draw(screen *ebiten.Image) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	screen.Fill(b.screen.AvailableColors[pkg.ColorBackground])
+
+	font := b.screen.Font
+	white := b.screen.AvailableColors[pkg.ColorText]
+
+	title := "JOIN LAN GAME"
+	drawer.DrawText(screen, title, font.H2, b.screen.AvailableColors[pkg.ColorTitle],
+		pkg.Position{X: float32(drawer.GetXCenterPos(b.screen.Width, title, font.H2Size)), Y: 80})
+
+	x, y := float32(100), float32(200)
+	drawer.DrawText(screen, fmt.Sprintf("%-20s %-24s %-10s %-8s %-6s %-8s %s", "NAME", "ADDR", "VERSION", "RULES", "SLOTS", "SECURED", "PING"),
+		font.Text, b.screen.AvailableColors[pkg.ColorAccent2], pkg.Position{X: x, Y: y})
+
+	if len(b.servers) == 0 {
+		drawer.DrawText(screen, "Searching servers...", font.Text, white, pkg.Position{X: x, Y: y + 40})
+	}
+	for i, server := range b.servers {
+		y += 40
+		line := fmt.Sprintf("%-20.20s %-24s %-10.10s %-8s %-6d %-8t %dms",
+			server.Name, server.Addr, server.Version, server.Rules.String(), server.FreeSlots, server.Secured, server.Ping.Milliseconds())
+		if i == b.selected {
+			drawer.DrawRectangle(screen, b.screen.Width-2*int(x)+20, 30, pkg.Position{X: x - 10, Y: y - 8}, b.screen.AvailableColors[pkg.ColorAccent1])
+		}
+		drawer.DrawText(screen, line, font.Text, white, pkg.Position{X: x, Y: y})
+	}
+
+	if b.message != "" {
+		drawer.DrawText(screen, b.message, font.Text, b.screen.AvailableColors[pkg.ColorAccent3],
+			pkg.Position{X: x, Y: float32(b.screen.Height) - 120})
+	}
+
+	help := "[up/down] select - [enter] join - [r] refresh - [esc] back"
+	drawer.DrawText(screen, help, font.TinyText, white,
+		pkg.Position{X: float32(drawer.GetXCenterPos(b.screen.Width, help, font.TinyTextSize)), Y: float32(b.screen.Height) - 60})
+}
+
+func (b *Browser) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	if b.joined != nil {
+		return b.joined.Drawer().Layout(outsideWidth, outsideHeight)
+	}
+	return b.letterbox.Layout(b.screen, outsideWidth, outsideHeight)
+}
@@ -19,6 +19,18 @@ func NewPGame(debug bool, version string) *LocalPGame {
 	}
 }
 
+// NewBotPGame builds a local game against the computer which plays the right paddle
+func NewBotPGame(debug bool, version string) *LocalPGame {
+	game := pkg.NewGame(pkg.LocalMode, debug)
+	game.PlayerR.Name = "Computer"
+	return &LocalPGame{
+		drawer: drawer.NewDrawerGame(
+			game,
+			func(network.Message) {}, func() {},
+			version).WithBot(pkg.NewBot(pkg.PlayerRight)),
+	}
+}
+
 // Title returns the console title
 func (pg *LocalPGame) Title() string {
 	return pg.drawer.Game.Title.Text
@@ -28,3 +40,6 @@ func (pg *LocalPGame) Title() string {
 func (pg *LocalPGame) Drawer() *drawer.GameDrawer {
 	return pg.drawer
 }
+
+// Close shuts the game down, a local game has nothing to release
+func (pg *LocalPGame) Close() {}
@@ -0,0 +1,204 @@
+package menu
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/pong/internal/drawer"
+	"github.com/joakim-ribier/pong/internal/game"
+	"github.com/joakim-ribier/pong/internal/game/lan"
+	"github.com/joakim-ribier/pong/internal/game/local"
+	"github.com/joakim-ribier/pong/internal/game/online"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/network/netsim"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+var logger = logging.For(logging.App)
+
+// Options are the options of the command line used to build the games from the main menu
+type Options struct {
+	Debug      bool
+	Version    string
+	Secret     string
+	Conditions netsim.Conditions
+	// Rollback plays the hosted matches with the rollback netcode delayed of {InputDelay} frames
+	Rollback   bool
+	InputDelay int
+}
+
+// item is an item of the main menu
+type item int
+
+const (
+	itemLocal item = iota
+	itemBot
+	itemHost
+	itemJoin
+	itemLAN
+	itemReplays
+	itemStats
+	itemSettings
+	itemQuit
+	nbItems
+)
+
+// String returns the label of the item
+func (i item) String() string {
+	switch i {
+	case itemLocal:
+		return "LOCAL 2 PLAYERS"
+	case itemBot:
+		return "VS COMPUTER"
+	case itemHost:
+		return "HOST ONLINE GAME"
+	case itemJoin:
+		return "JOIN ONLINE GAME"
+	case itemLAN:
+		return "JOIN LAN GAME"
+	case itemReplays:
+		return "REPLAYS"
+	case itemStats:
+		return "STATS"
+	case itemSettings:
+		return "SETTINGS"
+	case itemQuit:
+		return "QUIT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MainMenu is the first scene of the application, it opens each game mode and the other pages
+type MainMenu struct {
+	page
+	options  Options
+	manager  *game.Manager
+	selected item
+	stats    *sessionStats
+}
+
+// NewManager builds the scene manager of the application with the main menu as first scene
+func NewManager(options Options) *game.Manager {
+	m := &MainMenu{page: newPage(options.Debug), options: options, stats: &sessionStats{}}
+	pkg.ListenGames(m.stats.subscribe)
+	m.manager = game.NewManager(m)
+	return m.manager
+}
+
+// Title returns the console title
+func (m *MainMenu) Title() string {
+	return pkg.GameTitle
+}
+
+func (m *MainMenu) Update() error {
+	drawer.UpdateFullscreen()
+	m.selected = item(move(int(m.selected), int(nbItems)))
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		if m.selected == itemQuit {
+			return ebiten.Termination
+		}
+		m.open(m.selected)
+	}
+	return nil
+}
+
+// open runs the scene of the {item}
+func (m *MainMenu) open(i item) {
+	debug, version := m.options.Debug, m.options.Version
+	switch i {
+	case itemLocal:
+		m.manager.Push(game.FromPGame(local.NewPGame(debug, version)))
+	case itemBot:
+		m.manager.Push(game.FromPGame(local.NewBotPGame(debug, version)))
+	case itemHost:
+		m.manager.Push(newPrompt(debug, "HOST ONLINE GAME", "ADDRESS", ":3000", func(addr string) error {
+			return m.play(pkg.RemoteServerMode, addr)
+		}))
+	case itemJoin:
+		m.manager.Push(newPrompt(debug, "JOIN ONLINE GAME", "SERVER", "localhost:3000", func(addr string) error {
+			return m.play(pkg.RemoteClientMode, addr)
+		}))
+	case itemLAN:
+		m.manager.Push(lan.NewBrowser(debug, version, m.options.Secret, m.options.Conditions))
+	case itemReplays:
+		m.manager.Push(newInfo(debug, "REPLAYS", []string{"No replay recorded yet..."}))
+	case itemStats:
+		m.manager.Push(newInfo(debug, "STATS", m.stats.lines()))
+	case itemSettings:
+		m.manager.Push(newSettings(debug))
+	}
+}
+
+// play runs an online game in the {mode} mode on the {addr} address
+func (m *MainMenu) play(mode pkg.GameMode, addr string) error {
+	pg, err := online.NewPGame(m.options.Debug, mode, addr, m.options.Version, m.options.Secret, "", m.options.Conditions)
+	if err != nil {
+		logger.Warn("fail to start the online game", "addr", addr, "err", err)
+		return fmt.Errorf("fail to start the game on %s", addr)
+	}
+	if m.options.Rollback && mode == pkg.RemoteServerMode {
+		pg.GameDrawer.WithRollback(m.options.InputDelay)
+	}
+	m.manager.Push(game.FromPGame(pg))
+	return nil
+}
+
+func (m *MainMenu) Draw(screen *ebiten.Image) {
+	lines := make([]string, 0, nbItems)
+	for i := range nbItems {
+		lines = append(lines, i.String())
+	}
+	m.draw(screen, fmt.Sprintf("v%s", m.options.Version), lines, int(m.selected), "", "[up/down] select - [enter] open - [F11] fullscreen")
+}
+
+// sessionStats are the stats of the matches played since the start of the application
+type sessionStats struct {
+	mu       sync.Mutex
+	matches  int
+	wins     map[pkg.PlayerSide]int
+	sets     int
+	points   int
+	maxSpeed float32
+}
+
+// subscribe counts the stats from the {events} of a new game
+func (s *sessionStats) subscribe(events *pkg.Events) {
+	events.Listen(func(e pkg.Event) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch e := e.(type) {
+		case pkg.MatchWon:
+			if s.wins == nil {
+				s.wins = make(map[pkg.PlayerSide]int)
+			}
+			s.matches++
+			s.wins[e.Side]++
+		case pkg.SetEnded:
+			s.sets++
+		case pkg.PointScored:
+			s.points++
+		case pkg.PaddleHit:
+			// the hits simulated again by the rollback netcode do not change the max speed
+			s.maxSpeed = max(s.maxSpeed, e.Speed, -e.Speed)
+		}
+	})
+}
+
+// lines returns the stats to display
+func (s *sessionStats) lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return []string{
+		fmt.Sprintf("%-14s%d", "MATCHES", s.matches),
+		fmt.Sprintf("%-14s%d - %d", "WINS L/R", s.wins[pkg.PlayerLeft], s.wins[pkg.PlayerRight]),
+		fmt.Sprintf("%-14s%d", "SETS", s.sets),
+		fmt.Sprintf("%-14s%d", "POINTS", s.points),
+		fmt.Sprintf("%-14s%0.02f", "MAX SPEED", s.maxSpeed),
+	}
+}
@@ -0,0 +1,30 @@
+package menu
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+func TestSessionStats(t *testing.T) {
+	stats := &sessionStats{}
+	events := &pkg.Events{}
+	stats.subscribe(events)
+
+	events.Publish(pkg.PaddleHit{Side: pkg.PlayerLeft, Speed: -7.5})
+	events.Publish(pkg.PointScored{Side: pkg.PlayerLeft})
+	events.Publish(pkg.SetEnded{Number: 1, Side: pkg.PlayerLeft})
+	events.Publish(pkg.MatchWon{Side: pkg.PlayerLeft})
+
+	expected := []string{
+		"MATCHES       1",
+		"WINS L/R      1 - 0",
+		"SETS          1",
+		"POINTS        1",
+		"MAX SPEED     7.50",
+	}
+	if lines := stats.lines(); !slices.Equal(lines, expected) {
+		t.Errorf("got %q, expected %q", lines, expected)
+	}
+}
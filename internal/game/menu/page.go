@@ -0,0 +1,121 @@
+package menu
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/pong/internal/drawer"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// page is the base of the menu scenes: a title, a list of lines with a selected one, a message and a help line
+type page struct {
+	screen    pkg.Screen
+	theme     string
+	settings  pkg.Settings
+	debug     bool
+	letterbox drawer.Letterbox
+}
+
+// newPage builds a new {page} type with the screen and the fonts of the current theme
+func newPage(debug bool) page {
+	return page{screen: pkg.NewThemedScreen(pkg.LocalMode), theme: pkg.CurrentTheme().Name, settings: pkg.CurrentSettings(), debug: debug}
+}
+
+// Close releases the resources of the page, a page has nothing to release
+func (p *page) Close() {}
+
+func (p *page) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return p.letterbox.Layout(p.screen, outsideWidth, outsideHeight)
+}
+
+// Size returns the size of the window
+func (p *page) Size() (int, int) {
+	return p.screen.Width, p.screen.Height
+}
+
+// draw draws the {title} and the {lines} (the {selected} one is highlighted, -1 for none) on the {screen}
+func (p *page) draw(screen *ebiten.Image, title string, lines []string, selected int, message, help string) {
+	if pkg.CurrentTheme().Name != p.theme || pkg.CurrentSettings() != p.settings {
+		// the theme, the palette or the UI scale was changed from the settings
+		*p = newPage(p.debug)
+	}
+
+	p.letterbox.Draw(screen, p.screen, func(screen *ebiten.Image) {
+		screen.Fill(p.screen.AvailableColors[pkg.ColorBackground])
+
+		font := p.screen.Font
+		white := p.screen.AvailableColors[pkg.ColorText]
+
+		drawer.DrawText(screen, pkg.GameTitle, font.H1, p.screen.AvailableColors[pkg.ColorTitle],
+			pkg.Position{X: float32(drawer.GetXCenterPos(p.screen.Width, pkg.GameTitle, font.H1Size)), Y: 60})
+		drawer.DrawText(screen, title, font.H2, p.screen.AvailableColors[pkg.ColorAccent2],
+			pkg.Position{X: float32(drawer.GetXCenterPos(p.screen.Width, title, font.H2Size)), Y: 180})
+
+		x, y := float32(p.screen.Width/2-220), float32(270)
+		for i, line := range lines {
+			if i == selected {
+				drawer.DrawRectangle(screen, 460, 30, pkg.Position{X: x - 20, Y: y - 8}, p.screen.AvailableColors[pkg.ColorAccent1])
+			}
+			drawer.DrawText(screen, line, font.Text, white, pkg.Position{X: x, Y: y})
+			y += 40
+		}
+
+		if message != "" {
+			drawer.DrawText(screen, message, font.Text, p.screen.AvailableColors[pkg.ColorAccent3],
+				pkg.Position{X: float32(drawer.GetXCenterPos(p.screen.Width, message, font.TextSize)), Y: float32(p.screen.Height) - 120})
+		}
+
+		drawer.DrawText(screen, help, font.TinyText, white,
+			pkg.Position{X: float32(drawer.GetXCenterPos(p.screen.Width, help, font.TinyTextSize)), Y: float32(p.screen.Height) - 60})
+	})
+}
+
+// move returns the {selected} line moved with [up/down] in a list of {nb} lines
+func move(selected, nb int) int {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		return (selected + nb - 1) % nb
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown), inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		return (selected + 1) % nb
+	}
+	return selected
+}
+
+// back returns {drawer.ErrMainMenu} on [escape] to go back to the main menu
+func back() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return drawer.ErrMainMenu
+	}
+	return nil
+}
+
+// info is a page which displays read-only lines (e.g. the stats of the session)
+type info struct {
+	page
+	title string
+	lines []string
+}
+
+// newInfo builds a new {info} type which displays the {lines}
+func newInfo(debug bool, title string, lines []string) *info {
+	return &info{page: newPage(debug), title: title, lines: lines}
+}
+
+// Title returns the console title
+func (i *info) Title() string {
+	return fmt.Sprintf("%s (%s)", pkg.GameTitle, i.title)
+}
+
+func (i *info) Update() error {
+	drawer.UpdateFullscreen()
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return drawer.ErrMainMenu
+	}
+	return back()
+}
+
+func (i *info) Draw(screen *ebiten.Image) {
+	i.draw(screen, i.title, i.lines, -1, "", "[esc] back")
+}
@@ -0,0 +1,66 @@
+package menu
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/pong/internal/drawer"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+// promptMaxLen is the max length of the typed value (e.g. an IPv6 address and its port)
+const promptMaxLen = 48
+
+// prompt is the page which asks the player for a value (e.g. the address of a server)
+// and which submits it on [enter]
+type prompt struct {
+	page
+	title   string
+	label   string
+	value   []rune
+	message string
+	submit  func(value string) error
+}
+
+// newPrompt builds a new {prompt} type with the default {value},
+// the error of the {submit} function is displayed to the player
+func newPrompt(debug bool, title, label, value string, submit func(value string) error) *prompt {
+	return &prompt{page: newPage(debug), title: title, label: label, value: []rune(value), submit: submit}
+}
+
+// Title returns the console title
+func (p *prompt) Title() string {
+	return fmt.Sprintf("%s (%s)", pkg.GameTitle, p.title)
+}
+
+func (p *prompt) Update() error {
+	drawer.UpdateFullscreen()
+
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if unicode.IsPrint(r) && !unicode.IsSpace(r) && len(p.value) < promptMaxLen {
+			p.value = append(p.value, r)
+		}
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(p.value) > 0:
+		p.value = p.value[:len(p.value)-1]
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if err := p.submit(string(p.value)); err != nil {
+			p.message = err.Error()
+		}
+	}
+	return back()
+}
+
+func (p *prompt) Draw(screen *ebiten.Image) {
+	cursor := ""
+	if time.Now().UnixMilli()/500%2 == 0 {
+		cursor = "_"
+	}
+	p.draw(screen, p.title, []string{fmt.Sprintf("%-9s%s%s", p.label, string(p.value), cursor)}, 0, p.message,
+		"[type] value - [enter] confirm - [esc] back")
+}
@@ -0,0 +1,115 @@
+package menu
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
+	"github.com/joakim-ribier/pong/internal/drawer"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+const (
+	settingVolume = iota
+	settingSound
+	settingFullscreen
+	settingTheme
+	settingEffects
+	settingUIScale
+	settingPalette
+	settingTrail
+	settingSlowBall
+	settingAudioCues
+	nbSettings
+)
+
+// settings is the page which changes the options of the player (the accessibility ones included) and the theme of the new games
+type settings struct {
+	page
+	selected int
+	message  string
+}
+
+// newSettings builds a new {settings} type
+func newSettings(debug bool) *settings {
+	return &settings{page: newPage(debug)}
+}
+
+// Title returns the console title
+func (s *settings) Title() string {
+	return fmt.Sprintf("%s (Settings)", pkg.GameTitle)
+}
+
+func (s *settings) Update() error {
+	drawer.UpdateFullscreen()
+	s.selected = move(s.selected, nbSettings)
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		s.change(-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight), inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		s.change(1)
+	}
+	return back()
+}
+
+// change selects the previous or next ({delta}) value of the selected setting
+func (s *settings) change(delta int) {
+	settings := pkg.CurrentSettings()
+	switch s.selected {
+	case settingVolume:
+		pkg.UseSettings(settings.WithVolume(float64(delta) / 10))
+	case settingSound:
+		settings.Mute = !settings.Mute
+		pkg.UseSettings(settings)
+	case settingFullscreen:
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+	case settingTheme:
+		themes := pkg.Themes()
+		next := themes[(slices.Index(themes, pkg.CurrentTheme().Name)+delta+len(themes))%len(themes)]
+		theme, err := pkg.LoadTheme(next)
+		if err != nil {
+			logging.For(logging.App).Warn("fail to load the theme", "theme", next, "err", err)
+			s.message = fmt.Sprintf("Fail to load the theme %s...", next)
+			return
+		}
+		pkg.UseTheme(theme)
+	case settingEffects:
+		settings.Effects = !settings.Effects
+		pkg.UseSettings(settings)
+	case settingUIScale:
+		pkg.UseSettings(settings.WithUIScale(delta))
+	case settingPalette:
+		pkg.UseSettings(settings.WithPalette(delta))
+	case settingTrail:
+		settings.HighContrastTrail = !settings.HighContrastTrail
+		pkg.UseSettings(settings)
+	case settingSlowBall:
+		settings.SlowBall = !settings.SlowBall
+		pkg.UseSettings(settings)
+	case settingAudioCues:
+		settings.AudioCues = !settings.AudioCues
+		pkg.UseSettings(settings)
+	}
+}
+
+func (s *settings) Draw(screen *ebiten.Image) {
+	settings := pkg.CurrentSettings()
+	onOff := map[bool]string{true: "on", false: "off"}
+	lines := []string{
+		fmt.Sprintf("%-12s%d%%", "VOLUME", int(settings.Volume*100+0.5)),
+		fmt.Sprintf("%-12s%s", "SOUND", onOff[!settings.Mute]),
+		fmt.Sprintf("%-12s%s", "FULLSCREEN", onOff[ebiten.IsFullscreen()]),
+		fmt.Sprintf("%-12s%s", "THEME", pkg.CurrentTheme().Name),
+		fmt.Sprintf("%-12s%s", "EFFECTS", onOff[settings.Effects]),
+		fmt.Sprintf("%-12s%d%%", "UI SCALE", int(settings.UIScale*100)),
+		fmt.Sprintf("%-12s%s", "PALETTE", genericsutil.OrElse(settings.Palette, func(v string) bool { return v != "" }, func() string { return "theme" })),
+		fmt.Sprintf("%-12s%s", "BALL TRAIL", onOff[settings.HighContrastTrail]),
+		fmt.Sprintf("%-12s%s", "SLOW BALL", onOff[settings.SlowBall]),
+		fmt.Sprintf("%-12s%s", "AUDIO CUES", onOff[settings.AudioCues]),
+	}
+	s.draw(screen, "SETTINGS", lines, s.selected, s.message, "[up/down] select - [left/right] change - [esc] back")
+}
@@ -2,44 +2,173 @@ package online
 
 import (
 	"fmt"
-	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
 	"github.com/joakim-ribier/pong/internal/drawer"
+	"github.com/joakim-ribier/pong/internal/logging"
 	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/internal/network/discovery"
+	"github.com/joakim-ribier/pong/internal/network/netsim"
 	"github.com/joakim-ribier/pong/internal/network/udp"
 	"github.com/joakim-ribier/pong/pkg"
 )
 
+var logger = logging.For(logging.Drawer)
+
+const (
+	// promotionTimeout is the time to wait for the former host to release its port
+	promotionTimeout = 5 * time.Second
+	// messagesBuffer absorbs the messages read by the connections while the game is closed
+	messagesBuffer = 64
+)
+
 type OnlinePGame struct {
 	GameDrawer *drawer.GameDrawer
 
-	server   network.Conn
-	client   network.Conn
+	// mu guards the connections, the port and the announcer which change when the client takes over the host
+	mu        sync.Mutex
+	server    network.Conn
+	client    network.Conn
+	announcer *discovery.Announcer
+	port      int
+
+	secret   string
 	messages chan network.Message
 	version  string
+
+	// done stops the messages goroutine when the game is closed
+	done     chan struct{}
+	stopping sync.Once
+	closing  sync.Once
+
+	conditions netsim.Conditions
 }
 
-func NewPGame(debug bool, mode pkg.GameMode, networkAddr, version string) *OnlinePGame {
+// NewPGame builds a new {OnlinePGame} type, it returns an error
+// if the {networkAddr} address can not be resolved or listened,
+// the server is announced on the LAN when the discovery port is available
+// and the room is secured by the {secret} shared secret if it is not empty,
+// the client resumes the {session} seat of a match it handed over if the token is not empty
+func NewPGame(debug bool, mode pkg.GameMode, networkAddr, version, secret, session string, conditions netsim.Conditions) (*OnlinePGame, error) {
 	pg := &OnlinePGame{
-		messages: make(chan network.Message),
-		version:  version,
+		messages:   make(chan network.Message, messagesBuffer),
+		done:       make(chan struct{}),
+		version:    version,
+		secret:     secret,
+		conditions: conditions,
 	}
 
+	if mode == pkg.RemoteServerMode {
+		server, err := udp.NewServer(networkAddr)
+		if err != nil {
+			return nil, err
+		}
+		secured, err := server.WithSecret(secret)
+		if err != nil {
+			server.Shutdown()
+			return nil, err
+		}
+		pg.server = simulate(secured, conditions)
+		pg.port = server.LocalAddr().(*net.UDPAddr).Port
+	} else if mode == pkg.RemoteClientMode {
+		client, err := udp.NewClient(networkAddr)
+		if err != nil {
+			return nil, err
+		}
+		pg.client = simulate(client.WithSecret(secret).WithSession(session), conditions)
+	}
+
+	pg.GameDrawer = drawer.NewDrawerGame(pkg.NewGame(mode, debug), pg.send, pg.shutdown, version).WithPromotion(pg.promote)
 	go pg.handleMessage()
-	pg.GameDrawer = drawer.NewDrawerGame(pkg.NewGame(mode, debug), pg.send, pg.shutdown, version)
 
 	if pg.GameDrawer.Game.IsRemoteServer() {
-		pg.server = udp.NewServer(networkAddr)
+		pg.announce()
 		go pg.server.ListenAndServe(pg.messages)
-		go pg.GameDrawer.Game.PlayerR.Remote()
 	} else if pg.GameDrawer.Game.IsRemoteClient() {
-		pg.client = udp.NewClient(networkAddr)
 		go pg.client.ListenAndServe(pg.messages)
-		go pg.GameDrawer.Game.PlayerL.Remote()
 	}
 
-	return pg
+	// the remote player can play on both sides (swap, host migration)
+	go pg.GameDrawer.Game.PlayerL.Remote()
+	go pg.GameDrawer.Game.PlayerR.Remote()
+
+	return pg, nil
+}
+
+// announce announces the server on the LAN if the discovery port is available
+func (pg *OnlinePGame) announce() {
+	announcer, err := discovery.NewAnnouncer(discovery.DefaultPort, pg.beacon)
+	if err != nil {
+		logger.Warn("the server will not be announced on the LAN", "err", err)
+		return
+	}
+	pg.mu.Lock()
+	pg.announcer = announcer
+	pg.mu.Unlock()
+	go announcer.Serve()
+}
+
+// promote turns the client into the server of the match on the {port} port of the former host,
+// it waits in background for the former host to release the port then it calls {done}
+func (pg *OnlinePGame) promote(port int, done func(err error)) {
+	go func() { done(pg.listen(port)) }()
+}
+
+// listen listens on the {port} port as soon as the former host releases it then it swaps the client connection for the server
+func (pg *OnlinePGame) listen(port int) error {
+	var server *udp.UDPServer
+	var err error
+	for deadline := time.Now().Add(promotionTimeout); ; time.Sleep(100 * time.Millisecond) {
+		// the former host releases the port while it quits
+		if server, err = udp.NewServer(fmt.Sprintf(":%d", port)); err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	secured, err := server.WithSecret(pg.secret)
+	if err != nil {
+		server.Shutdown()
+		return err
+	}
+	conn := simulate(secured, pg.conditions)
+	pg.mu.Lock()
+	client := pg.client
+	pg.server, pg.port = conn, port
+	pg.mu.Unlock()
+
+	// the client connection is closed in background, it waits for the messages in progress
+	go client.Shutdown()
+	pg.announce()
+	go conn.ListenAndServe(pg.messages)
+
+	logger.Info("take over the host", "port", port)
+	return nil
+}
+
+// conn returns the server connection once the game hosts the match, the client connection otherwise
+func (pg *OnlinePGame) conn() network.Conn {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	if pg.server != nil {
+		return pg.server
+	}
+	return pg.client
+}
+
+// simulate wraps the {conn} connection into a network simulator if {conditions} are enabled
+func simulate(conn network.Conn, conditions netsim.Conditions) network.Conn {
+	if conditions.Enabled() {
+		return netsim.Wrap(conn, conditions)
+	}
+	return conn
 }
 
 // Drawer returns the drawer that builds the game
@@ -59,24 +188,79 @@ func (pg *OnlinePGame) Title() string {
 		func() string { return fmt.Sprintf("%s (%s - client)", title, pg.GameDrawer.Game.PlayerR.Name) })
 }
 
-// handleMessage handles messages received from the network
+// beacon builds the announcement of the server on the LAN
+func (pg *OnlinePGame) beacon() discovery.Beacon {
+	announcement := pg.GameDrawer.Announcement()
+	name, err := os.Hostname()
+	if err != nil {
+		name = announcement.Host
+	}
+
+	pg.mu.Lock()
+	port := pg.port
+	pg.mu.Unlock()
+
+	return discovery.Beacon{
+		Name:      name,
+		Version:   pg.version,
+		Protocol:  network.ProtocolVersion,
+		Port:      port,
+		Secured:   pg.secret != "",
+		Rules:     announcement.Rules,
+		FreeSlots: announcement.FreeSlots,
+	}
+}
+
+// handleMessage hands the messages received from the network over to the drawer which handles them on its next frame,
+// it stops the remote paddles once the game is closed
 func (pg *OnlinePGame) handleMessage() {
-	for message := range pg.messages {
-		log.Printf("handle message from %s: %v", message.NetworkAddr, message)
-		pg.GameDrawer.HandleNetworkMessage(message)
+	for {
+		select {
+		case <-pg.done:
+			close(pg.GameDrawer.Game.PlayerL.UpdatePaddleY)
+			close(pg.GameDrawer.Game.PlayerR.UpdatePaddleY)
+			return
+		case message := <-pg.messages:
+			logger.Debug("handle message", "addr", message.NetworkAddr, "cmd", message.Data.Cmd, "value", message.Data.Value)
+			pg.GameDrawer.Receive(message)
+		}
 	}
 }
 
 // send sends a message to the network
 func (pg *OnlinePGame) send(msg network.Message) {
-	genericsutil.When[pkg.Game, network.Conn](
-		*pg.GameDrawer.Game, func(game pkg.Game) bool { return game.IsRemoteServer() },
-		func(g pkg.Game) network.Conn { return pg.server }, func() network.Conn { return pg.client }).Send(msg)
+	pg.conn().Send(msg)
+}
+
+// Close shuts the game down when the player leaves it, it turns off the network
+// and it stops the goroutines of the messages and of the remote paddles
+func (pg *OnlinePGame) Close() {
+	pg.closing.Do(func() {
+		pg.shutdown()
+		close(pg.done)
+	})
 }
 
-// shutdown turns off the network listener
+// shutdown turns off the network once (the drawer shuts it down before the game is closed)
 func (pg *OnlinePGame) shutdown() {
-	genericsutil.When[pkg.Game, network.Conn](
-		*pg.GameDrawer.Game, func(game pkg.Game) bool { return game.IsRemoteServer() },
-		func(g pkg.Game) network.Conn { return pg.server }, func() network.Conn { return pg.client }).Shutdown()
+	pg.stopping.Do(pg.stop)
+}
+
+// stop turns off the network listener and the LAN announcer,
+// it prints how to join back the match if it was handed over to the client
+func (pg *OnlinePGame) stop() {
+	pg.mu.Lock()
+	announcer, port := pg.announcer, pg.port
+	pg.mu.Unlock()
+
+	if announcer != nil {
+		announcer.Shutdown()
+	}
+	pg.conn().Shutdown()
+
+	if addr, token := pg.GameDrawer.HandedOver(); token != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		fmt.Fprintf(os.Stderr, "pong: the match was handed over, join it back with --client %s --session %s\n",
+			net.JoinHostPort(host, strconv.Itoa(port)), token)
+	}
 }
@@ -0,0 +1,80 @@
+package online
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/network/netsim"
+	"github.com/joakim-ribier/pong/internal/network/udp"
+	"github.com/joakim-ribier/pong/pkg"
+)
+
+func TestPromote(t *testing.T) {
+	// the former host listens on the port until it quits
+	host, err := udp.NewServer(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := host.LocalAddr().(*net.UDPAddr).Port
+
+	pg, err := NewPGame(false, pkg.RemoteClientMode, host.LocalAddr().String(), "test", "", "", netsim.Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pg.shutdown)
+
+	promoted := make(chan error, 1)
+	pg.promote(port, func(err error) { promoted <- err })
+
+	// the promotion waits in background for the port
+	select {
+	case err := <-promoted:
+		t.Fatalf("got %v before the former host released the port", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	host.Shutdown()
+	select {
+	case err := <-promoted:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(promotionTimeout):
+		t.Fatal("client should take over the port of the former host")
+	}
+
+	pg.mu.Lock()
+	server, bound := pg.server, pg.port
+	pg.mu.Unlock()
+	if server == nil || bound != port || pg.conn() != server {
+		t.Errorf("got the server %v on port %d, expected the port %d", server, bound, port)
+	}
+}
+
+func TestClose(t *testing.T) {
+	host, err := udp.NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Shutdown()
+
+	pg, err := NewPGame(false, pkg.RemoteClientMode, host.LocalAddr().String(), "test", "", "", netsim.Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the drawer shuts the network down before the game is closed, it is shut down once
+	pg.shutdown()
+	pg.Close()
+
+	for _, player := range []*pkg.Player{pg.GameDrawer.Game.PlayerL, pg.GameDrawer.Game.PlayerR} {
+		select {
+		case _, ok := <-player.UpdatePaddleY:
+			if ok {
+				t.Errorf("got a paddle update of the %s player after the close", player.Side)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("the remote paddle of the %s player should stop", player.Side)
+		}
+	}
+}
@@ -9,4 +9,6 @@ type PGame interface {
 	Title() string
 	// Drawer returns the drawer that builds the game
 	Drawer() *drawer.GameDrawer
+	// Close shuts the game down when the player leaves it
+	Close()
 }
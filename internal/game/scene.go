@@ -0,0 +1,101 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joakim-ribier/pong/internal/drawer"
+)
+
+// Scene is a screen of the application (main menu, game, LAN browser...) run by the {Manager}
+type Scene interface {
+	ebiten.Game
+	// Title returns the console title
+	Title() string
+	// Size returns the size of the window
+	Size() (int, int)
+	// Close releases the resources of the scene (e.g. the network of a game) when the manager drops it
+	Close()
+}
+
+// Manager runs the scenes of the application, it is the {ebiten.Game} of the application,
+// the last pushed scene is the current one and the first one is the main menu
+type Manager struct {
+	scenes   []Scene
+	setTitle func(title string)
+}
+
+// NewManager builds a new {Manager} type with the {root} scene (the main menu)
+func NewManager(root Scene) *Manager {
+	m := &Manager{setTitle: ebiten.SetWindowTitle}
+	m.Push(root)
+	return m
+}
+
+// Push runs the {scene} on top of the current one
+func (m *Manager) Push(scene Scene) {
+	m.scenes = append(m.scenes, scene)
+	m.setTitle(scene.Title())
+}
+
+// Back goes back to the main menu, the dropped scenes are closed
+func (m *Manager) Back() {
+	for i := len(m.scenes) - 1; i > 0; i-- {
+		m.scenes[i].Close()
+	}
+	m.scenes = m.scenes[:1]
+	m.setTitle(m.Current().Title())
+}
+
+// Current returns the scene currently running
+func (m *Manager) Current() Scene {
+	return m.scenes[len(m.scenes)-1]
+}
+
+// Update updates the current scene, the scene which returns {drawer.ErrMainMenu} goes back to the main menu
+func (m *Manager) Update() error {
+	err := m.Current().Update()
+	if errors.Is(err, drawer.ErrMainMenu) {
+		m.Back()
+		return nil
+	}
+	return err
+}
+
+func (m *Manager) Draw(screen *ebiten.Image) {
+	m.Current().Draw(screen)
+}
+
+func (m *Manager) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return m.Current().Layout(outsideWidth, outsideHeight)
+}
+
+// FromPGame turns the {pg} game into a scene
+func FromPGame(pg PGame) Scene {
+	return pgameScene{pg}
+}
+
+type pgameScene struct {
+	PGame
+}
+
+// Size returns the size of the window
+func (s pgameScene) Size() (int, int) {
+	return s.Drawer().Game.Screen.Width, s.Drawer().Game.Screen.Height
+}
+
+func (s pgameScene) Close() {
+	s.PGame.Close()
+}
+
+func (s pgameScene) Update() error {
+	return s.Drawer().Update()
+}
+
+func (s pgameScene) Draw(screen *ebiten.Image) {
+	s.Drawer().Draw(screen)
+}
+
+func (s pgameScene) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return s.Drawer().Layout(outsideWidth, outsideHeight)
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joakim-ribier/pong/internal/drawer"
+)
+
+type fakeScene struct {
+	title  string
+	err    error
+	closed int
+}
+
+func (s *fakeScene) Update() error              { return s.err }
+func (s *fakeScene) Draw(*ebiten.Image)         {}
+func (s *fakeScene) Layout(w, h int) (int, int) { return w, h }
+func (s *fakeScene) Title() string              { return s.title }
+func (s *fakeScene) Size() (int, int)           { return 0, 0 }
+func (s *fakeScene) Close()                     { s.closed++ }
+
+func TestManager(t *testing.T) {
+	var titles []string
+	root := &fakeScene{title: "menu"}
+	m := &Manager{setTitle: func(title string) { titles = append(titles, title) }}
+	m.Push(root)
+
+	prompt := &fakeScene{title: "prompt"}
+	m.Push(prompt)
+	game := &fakeScene{title: "game"}
+	m.Push(game)
+	if m.Current() != game {
+		t.Fatalf("got %s, expected the game", m.Current().Title())
+	}
+
+	// the game goes back to the main menu, not to the prompt
+	game.err = drawer.ErrMainMenu
+	if err := m.Update(); err != nil || m.Current() != root {
+		t.Fatalf("got %v and %s, expected the main menu", err, m.Current().Title())
+	}
+	if last := titles[len(titles)-1]; last != "menu" {
+		t.Errorf("got title %s, expected menu", last)
+	}
+	// the dropped scenes are closed (e.g. the network of an online game), not the main menu
+	if game.closed != 1 || prompt.closed != 1 || root.closed != 0 {
+		t.Errorf("got %d, %d and %d closes of the game, the prompt and the menu", game.closed, prompt.closed, root.closed)
+	}
+
+	// the other errors stop the application
+	root.err = ebiten.Termination
+	if err := m.Update(); !errors.Is(err, ebiten.Termination) {
+		t.Errorf("got %v, expected the termination", err)
+	}
+}
@@ -0,0 +1,200 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Subsystem represents the part of the application which produces a log
+type Subsystem string
+
+const (
+	AntiCheat Subsystem = "anticheat"
+	Audio     Subsystem = "audio"
+	App       Subsystem = "app"
+	Channel   Subsystem = "channel"
+	Discovery Subsystem = "discovery"
+	Drawer    Subsystem = "drawer"
+	GameState Subsystem = "game.state"
+	Hub       Subsystem = "hub"
+	Metrics   Subsystem = "metrics"
+	NetSim    Subsystem = "netsim"
+	Ticker    Subsystem = "ticker"
+	UDPClient Subsystem = "udp.client"
+	UDPServer Subsystem = "udp.server"
+)
+
+// Subsystems are the subsystems which can be filtered
+var Subsystems = []Subsystem{AntiCheat, Audio, App, Channel, Discovery, Drawer, GameState, Hub, Metrics, NetSim, Ticker, UDPClient, UDPServer}
+
+// Options represents the logging configuration
+type Options struct {
+	// Output is the destination of the logs (nil to discard them)
+	Output io.Writer
+	// Format is the output format [text|json]
+	Format string
+	// Level is the default minimum level
+	Level slog.Level
+	// Filters overrides the minimum level per subsystem
+	Filters map[Subsystem]slog.Level
+}
+
+// config represents the current pipeline shared by all the loggers
+type config struct {
+	handler slog.Handler
+	level   slog.Level
+	filters map[Subsystem]slog.Level
+}
+
+var (
+	current = config{handler: discardHandler{}, level: slog.LevelInfo}
+	mu      sync.RWMutex
+)
+
+// Setup configures the logging pipeline used by all the subsystem loggers
+func Setup(opts Options) error {
+	var handler slog.Handler = discardHandler{}
+	if opts.Output != nil {
+		handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
+		switch opts.Format {
+		case "", "text":
+			handler = slog.NewTextHandler(opts.Output, handlerOpts)
+		case "json":
+			handler = slog.NewJSONHandler(opts.Output, handlerOpts)
+		default:
+			return fmt.Errorf("unknown log format '%s' (text|json)", opts.Format)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	current = config{handler: handler, level: opts.Level, filters: opts.Filters}
+	slog.SetDefault(For(App))
+
+	return nil
+}
+
+// OpenFile opens (or creates) the {filename} file to append logs
+func OpenFile(filename string) (*os.File, error) {
+	return os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+// ParseLevel parses the {s} level [debug|info|warn|error]
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return level, fmt.Errorf("unknown log level '%s' (debug|info|warn|error)", s)
+	}
+	return level, nil
+}
+
+// ParseFilters parses the {s} per-subsystem levels [hub=warn,udp.server=debug]
+func ParseFilters(s string) (map[Subsystem]slog.Level, error) {
+	filters := make(map[Subsystem]slog.Level)
+	for _, filter := range strings.Split(s, ",") {
+		if filter = strings.TrimSpace(filter); filter == "" {
+			continue
+		}
+
+		subsystem, value, ok := strings.Cut(filter, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid log filter '%s' (subsystem=level)", filter)
+		}
+		if !slices.Contains(Subsystems, Subsystem(subsystem)) {
+			return nil, fmt.Errorf("unknown log subsystem '%s' %v", subsystem, Subsystems)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		filters[Subsystem(subsystem)] = level
+	}
+	return filters, nil
+}
+
+// For returns the logger of the {subsystem}, the {sinks} handlers receive
+// every record of the logger whatever the configured levels
+func For(subsystem Subsystem, sinks ...slog.Handler) *slog.Logger {
+	return slog.New(&subsystemHandler{subsystem: subsystem, sinks: sinks})
+}
+
+// subsystemHandler is a {slog.Handler} which filters records by subsystem
+// and delegates them to the current pipeline
+type subsystemHandler struct {
+	subsystem Subsystem
+	sinks     []slog.Handler
+	wrap      []func(slog.Handler) slog.Handler
+}
+
+func (h *subsystemHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return len(h.sinks) > 0 || level >= h.minLevel()
+}
+
+func (h *subsystemHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, sink := range h.sinks {
+		if err := h.build(sink).Handle(ctx, r.Clone()); err != nil {
+			return err
+		}
+	}
+
+	if r.Level < h.minLevel() {
+		return nil
+	}
+
+	mu.RLock()
+	handler := current.handler
+	mu.RUnlock()
+
+	return h.build(handler.WithAttrs([]slog.Attr{slog.String("subsystem", string(h.subsystem))})).Handle(ctx, r)
+}
+
+func (h *subsystemHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *subsystemHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *subsystemHandler) with(wrap func(slog.Handler) slog.Handler) *subsystemHandler {
+	return &subsystemHandler{
+		subsystem: h.subsystem,
+		sinks:     h.sinks,
+		wrap:      append(append([]func(slog.Handler) slog.Handler{}, h.wrap...), wrap),
+	}
+}
+
+// build applies the attributes and groups of the logger to the {handler}
+func (h *subsystemHandler) build(handler slog.Handler) slog.Handler {
+	for _, wrap := range h.wrap {
+		handler = wrap(handler)
+	}
+	return handler
+}
+
+// minLevel returns the minimum level of the subsystem
+func (h *subsystemHandler) minLevel() slog.Level {
+	mu.RLock()
+	defer mu.RUnlock()
+	if _, ok := current.handler.(discardHandler); ok {
+		return slog.LevelError + 1
+	}
+	if level, ok := current.filters[h.subsystem]; ok {
+		return level
+	}
+	return current.level
+}
+
+// discardHandler is a {slog.Handler} which drops all records
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
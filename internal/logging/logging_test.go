@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected slog.Level
+		err      bool
+	}{
+		{s: "debug", expected: slog.LevelDebug},
+		{s: "info", expected: slog.LevelInfo},
+		{s: "warn", expected: slog.LevelWarn},
+		{s: "ERROR", expected: slog.LevelError},
+		{s: "", err: true},
+		{s: "verbose", err: true},
+	}
+	for _, tt := range tests {
+		level, err := ParseLevel(tt.s)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseLevel(%q) got the error %v", tt.s, err)
+			continue
+		}
+		if !tt.err && level != tt.expected {
+			t.Errorf("ParseLevel(%q) = %v, expected %v", tt.s, level, tt.expected)
+		}
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected map[Subsystem]slog.Level
+		err      bool
+	}{
+		{s: "", expected: map[Subsystem]slog.Level{}},
+		{s: "hub=warn", expected: map[Subsystem]slog.Level{Hub: slog.LevelWarn}},
+		{s: " hub=warn , udp.server=debug,", expected: map[Subsystem]slog.Level{Hub: slog.LevelWarn, UDPServer: slog.LevelDebug}},
+		{s: "hub", err: true},
+		{s: "hub=verbose", err: true},
+		{s: "netwrok=debug", err: true},
+	}
+	for _, tt := range tests {
+		filters, err := ParseFilters(tt.s)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseFilters(%q) got the error %v", tt.s, err)
+			continue
+		}
+		if !tt.err && !maps.Equal(filters, tt.expected) {
+			t.Errorf("ParseFilters(%q) = %v, expected %v", tt.s, filters, tt.expected)
+		}
+	}
+}
+
+func TestLevelPerSubsystem(t *testing.T) {
+	defer Setup(Options{})
+
+	var out bytes.Buffer
+	err := Setup(Options{
+		Output:  &out,
+		Level:   slog.LevelInfo,
+		Filters: map[Subsystem]slog.Level{Hub: slog.LevelWarn, UDPServer: slog.LevelDebug},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subsystem Subsystem
+		level     slog.Level
+		logged    bool
+	}{
+		{subsystem: App, level: slog.LevelDebug, logged: false},
+		{subsystem: App, level: slog.LevelInfo, logged: true},
+		{subsystem: Hub, level: slog.LevelInfo, logged: false},
+		{subsystem: Hub, level: slog.LevelWarn, logged: true},
+		{subsystem: UDPServer, level: slog.LevelDebug, logged: true},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		For(tt.subsystem).Log(context.Background(), tt.level, "message")
+		if logged := strings.Contains(out.String(), "subsystem="+string(tt.subsystem)); logged != tt.logged {
+			t.Errorf("got %t for the %v level of %s, expected %t", logged, tt.level, tt.subsystem, tt.logged)
+		}
+	}
+
+	// the sinks receive the records under the configured level
+	var sink bytes.Buffer
+	For(Hub, slog.NewTextHandler(&sink, &slog.HandlerOptions{Level: slog.LevelDebug})).Debug("message")
+	if sink.Len() == 0 {
+		t.Error("got no record in the sink")
+	}
+}
+
+func TestSetupUnknownFormat(t *testing.T) {
+	if err := Setup(Options{Output: &bytes.Buffer{}, Format: "xml"}); err == nil {
+		t.Error("expected an error for the xml format")
+	}
+}
@@ -0,0 +1,237 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// collector represents a metric which can be exposed in the Prometheus text format
+type collector interface {
+	write(w io.Writer)
+}
+
+// Registry represents a set of metrics to expose
+type Registry struct {
+	collectors []collector
+	mu         sync.Mutex
+}
+
+// NewRegistry builds a new {Registry} type
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// register adds the {c} collector to the registry
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes all the registered metrics in the Prometheus text format
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.collectors {
+		c.write(w)
+	}
+}
+
+// Counter represents a monotonically increasing value partitioned by an optional label
+type Counter struct {
+	name, help, label string
+	values            map[string]float64
+	mu                sync.Mutex
+}
+
+// NewCounter builds and registers a new {Counter} type
+func (r *Registry) NewCounter(name, help, label string) *Counter {
+	c := &Counter{name: name, help: help, label: label, values: make(map[string]float64)}
+	r.register(c)
+	return c
+}
+
+// Inc increments the counter (without label)
+func (c *Counter) Inc() {
+	c.With("").Inc()
+}
+
+// Delete removes the {labelValue} serie from the counter
+func (c *Counter) Delete(labelValue string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, labelValue)
+}
+
+// With returns the counter value for the {labelValue} label
+func (c *Counter) With(labelValue string) counterValue {
+	return counterValue{counter: c, labelValue: labelValue}
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writeHeader(w, c.name, c.help, "counter")
+	for _, labelValue := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labels(c.label, labelValue), formatFloat(c.values[labelValue]))
+	}
+}
+
+// counterValue represents a counter for a specific label value
+type counterValue struct {
+	counter    *Counter
+	labelValue string
+}
+
+// Inc increments the counter
+func (v counterValue) Inc() {
+	v.Add(1)
+}
+
+// Add adds the {delta} value to the counter
+func (v counterValue) Add(delta float64) {
+	v.counter.mu.Lock()
+	defer v.counter.mu.Unlock()
+	v.counter.values[v.labelValue] += delta
+}
+
+// Gauge represents a value which can go up and down partitioned by an optional label
+type Gauge struct {
+	name, help, label string
+	values            map[string]float64
+	mu                sync.Mutex
+}
+
+// NewGauge builds and registers a new {Gauge} type
+func (r *Registry) NewGauge(name, help, label string) *Gauge {
+	g := &Gauge{name: name, help: help, label: label, values: make(map[string]float64)}
+	r.register(g)
+	return g
+}
+
+// Set sets the gauge value (without label)
+func (g *Gauge) Set(v float64) {
+	g.With("").Set(v)
+}
+
+// Add adds the {delta} value to the gauge (without label)
+func (g *Gauge) Add(delta float64) {
+	g.With("").Add(delta)
+}
+
+// Delete removes the {labelValue} serie from the gauge
+func (g *Gauge) Delete(labelValue string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.values, labelValue)
+}
+
+// With returns the gauge value for the {labelValue} label
+func (g *Gauge) With(labelValue string) gaugeValue {
+	return gaugeValue{gauge: g, labelValue: labelValue}
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	writeHeader(w, g.name, g.help, "gauge")
+	for _, labelValue := range sortedKeys(g.values) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, labels(g.label, labelValue), formatFloat(g.values[labelValue]))
+	}
+}
+
+// gaugeValue represents a gauge for a specific label value
+type gaugeValue struct {
+	gauge      *Gauge
+	labelValue string
+}
+
+// Set sets the gauge value
+func (v gaugeValue) Set(value float64) {
+	v.gauge.mu.Lock()
+	defer v.gauge.mu.Unlock()
+	v.gauge.values[v.labelValue] = value
+}
+
+// Add adds the {delta} value to the gauge
+func (v gaugeValue) Add(delta float64) {
+	v.gauge.mu.Lock()
+	defer v.gauge.mu.Unlock()
+	v.gauge.values[v.labelValue] += delta
+}
+
+// Histogram represents a distribution of observed values into cumulative buckets
+type Histogram struct {
+	name, help string
+	buckets    []float64
+	counts     []uint64
+	count      uint64
+	sum        float64
+	mu         sync.Mutex
+}
+
+// NewHistogram builds and registers a new {Histogram} type
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+	r.register(h)
+	return h
+}
+
+// Observe adds the {v} value to the distribution
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bucket := range h.buckets {
+		if v <= bucket {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	writeHeader(w, h.name, h.help, "histogram")
+	for i, bucket := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels("le", formatFloat(bucket)), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels("le", "+Inf"), h.count)
+	fmt.Fprintf(w, "%s_sum %s\n", h.name, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count %d\n", h.name, h.count)
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// labels formats the {name=value} label or nothing if there is no label
+func labels(name, value string) string {
+	if name == "" {
+		return ""
+	}
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return fmt.Sprintf(`{%s="%s"}`, name, value)
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return fmt.Sprintf("%g", v)
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
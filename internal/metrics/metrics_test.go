@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	r := NewRegistry()
+	messages := r.NewCounter("test_messages_total", "Number of messages.", "cmd")
+	rtt := r.NewGauge("test_rtt_seconds", "Round-trip time.", "client")
+	durations := r.NewHistogram("test_duration_seconds", "Durations.", []float64{1, 5})
+
+	messages.With("Ping").Inc()
+	messages.With("Ping").Add(2)
+	messages.With(`Un"known`).Inc()
+	rtt.With("127.0.0.1:3000").Set(0.25)
+	rtt.With("127.0.0.1:4000").Set(0.5)
+	rtt.Delete("127.0.0.1:4000")
+	durations.Observe(0.5)
+	durations.Observe(3)
+	durations.Observe(10)
+
+	var sb strings.Builder
+	r.Write(&sb)
+
+	expected := `# HELP test_messages_total Number of messages.
+# TYPE test_messages_total counter
+test_messages_total{cmd="Ping"} 3
+test_messages_total{cmd="Un\"known"} 1
+# HELP test_rtt_seconds Round-trip time.
+# TYPE test_rtt_seconds gauge
+test_rtt_seconds{client="127.0.0.1:3000"} 0.25
+# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="1"} 1
+test_duration_seconds_bucket{le="5"} 2
+test_duration_seconds_bucket{le="+Inf"} 3
+test_duration_seconds_sum 13.5
+test_duration_seconds_count 3
+`
+	if sb.String() != expected {
+		t.Errorf("got\n%s\nexpected\n%s", sb.String(), expected)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	MessagesIn.With("Ping").Inc()
+	PingFailures.With("127.0.0.1:3000").Inc()
+	PingFailures.Delete("127.0.0.1:3000")
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := w.Body.String()
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("got the content type %q", ct)
+	}
+	for _, line := range []string{"# TYPE pong_messages_in_total counter", `pong_messages_in_total{cmd="Ping"}`, "# TYPE pong_rtt_seconds histogram"} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing %q in\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, `pong_ping_failures_total{client="127.0.0.1:3000"}`) {
+		t.Errorf("got the deleted serie in\n%s", body)
+	}
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/joakim-ribier/pong/internal/logging"
+)
+
+// Default is the registry exposed by the metrics endpoint
+var Default = NewRegistry()
+
+var (
+	// Subscribers is the number of subscribers connected to the server hub
+	Subscribers = Default.NewGauge("pong_subscribers", "Number of subscribers connected to the server.", "")
+	// ClientRTT is the last round-trip time computed for each client
+	ClientRTT = Default.NewGauge("pong_client_rtt_seconds", "Last ping/pong round-trip time per client.", "client")
+	// RTT is the distribution of the round-trip times of all clients
+	RTT = Default.NewHistogram("pong_rtt_seconds", "Distribution of the ping/pong round-trip times.",
+		[]float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5})
+	// PingFailures is the number of ping attempts without pong per client
+	PingFailures = Default.NewCounter("pong_ping_failures_total", "Number of ping attempts not answered per client.", "client")
+	// MessagesIn is the number of messages read from the network per command (the unknown commands share the "Unknown" label)
+	MessagesIn = Default.NewCounter("pong_messages_in_total", "Number of messages read from the network per command.", "cmd")
+	// MessagesOut is the number of messages sent to the network per command
+	MessagesOut = Default.NewCounter("pong_messages_out_total", "Number of messages sent to the network per command.", "cmd")
+	// DecodeErrors is the number of messages which can not be decoded
+	DecodeErrors = Default.NewCounter("pong_decode_errors_total", "Number of network messages which fail to be decoded.", "")
+	// RejectedDatagrams is the number of datagrams rejected because they are not authenticated
+	RejectedDatagrams = Default.NewCounter("pong_rejected_datagrams_total", "Number of unauthenticated datagrams rejected by the server.", "")
+	// Violations is the number of invalid inputs sent by the clients per command
+	Violations = Default.NewCounter("pong_violations_total", "Number of invalid inputs sent by the clients per command.", "cmd")
+	// DroppedBroadcasts is the number of hub broadcasts dropped because the subscriber was not ready
+	DroppedBroadcasts = Default.NewCounter("pong_hub_dropped_broadcasts_total", "Number of hub broadcasts dropped.", "")
+	// Rollbacks is the number of mispredicted remote inputs which rolled the simulation back
+	Rollbacks = Default.NewCounter("pong_rollbacks_total", "Number of rollbacks of the rollback netcode.", "")
+	// ActiveMatches is the number of matches in progress
+	ActiveMatches = Default.NewGauge("pong_active_matches", "Number of matches in progress.", "")
+	// MatchDuration is the distribution of the matches duration
+	MatchDuration = Default.NewHistogram("pong_match_duration_seconds", "Distribution of the matches duration.",
+		[]float64{30, 60, 120, 300, 600, 900, 1800, 3600})
+)
+
+// Handler returns the HTTP handler which exposes the {Default} registry in the Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Default.Write(w)
+	})
+}
+
+// ListenAndServe exposes the {Default} registry on the {addr}/metrics HTTP endpoint
+func ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	logging.For(logging.Metrics).Info("expose metrics", "url", "http://"+addr+"/metrics")
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,43 @@
+package network
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// ChatNameMaxLen is the max length of the name of a chat message sender
+	ChatNameMaxLen = 16
+	// ChatTextMaxLen is the max length of the text of a chat message
+	ChatTextMaxLen = 60
+)
+
+// ChatMessage represents a [Chat] message sent by a player
+type ChatMessage struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Text  string `json:"text"`
+}
+
+// Sanitize removes the non printable characters and truncates the fields of the chat message
+func (m ChatMessage) Sanitize() ChatMessage {
+	return ChatMessage{
+		Name:  sanitize(m.Name, ChatNameMaxLen),
+		Color: sanitize(m.Color, len("#rrggbb")),
+		Text:  sanitize(m.Text, ChatTextMaxLen),
+	}
+}
+
+func sanitize(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return -1
+	}, strings.TrimSpace(s))
+
+	if runes := []rune(s); len(runes) > maxLen {
+		return string(runes[:maxLen])
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+package network
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChatMessageSanitize(t *testing.T) {
+	m := ChatMessage{
+		Name:  "  Player\x1b[31m R  ",
+		Color: "#78e2a0",
+		Text:  "gg\n" + strings.Repeat("é", ChatTextMaxLen),
+	}.Sanitize()
+
+	if m.Name != "Player[31m R" {
+		t.Errorf("name: got %q", m.Name)
+	}
+	if m.Color != "#78e2a0" {
+		t.Errorf("color: got %q", m.Color)
+	}
+	if !strings.HasPrefix(m.Text, "gg") || len([]rune(m.Text)) != ChatTextMaxLen {
+		t.Errorf("text: got %q (%d runes)", m.Text, len([]rune(m.Text)))
+	}
+}
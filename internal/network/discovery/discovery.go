@@ -0,0 +1,154 @@
+package discovery
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/network"
+)
+
+// DefaultPort is the UDP port used to discover the servers on the LAN
+const DefaultPort = 3999
+
+var logger = logging.For(logging.Discovery)
+
+// Beacon represents the announcement of a server on the LAN
+type Beacon struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Version   string        `json:"version"`
+	Protocol  int           `json:"protocol"`
+	Port      int           `json:"port"`
+	Secured   bool          `json:"secured"`
+	Rules     network.Rules `json:"rules"`
+	FreeSlots int           `json:"freeSlots"`
+}
+
+// Server represents a server discovered on the LAN
+type Server struct {
+	Beacon
+	Addr string
+	Ping time.Duration
+}
+
+// Announcer answers to the discovery requests with the beacon of the server
+type Announcer struct {
+	conn   *net.UDPConn
+	id     string
+	beacon func() Beacon
+}
+
+// NewAnnouncer builds a new {Announcer} type which listens on the {port} discovery port
+func NewAnnouncer(port int, beacon func() Beacon) (*Announcer, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: port})
+	if err != nil {
+		return nil, fmt.Errorf("fail to listen on the discovery port %d: %w", port, err)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &Announcer{conn: conn, id: hex.EncodeToString(id), beacon: beacon}, nil
+}
+
+// LocalAddr returns the address on which the announcer listens
+func (a *Announcer) LocalAddr() net.Addr {
+	return a.conn.LocalAddr()
+}
+
+// Serve answers to the discovery requests until the announcer is shutdown
+func (a *Announcer) Serve() {
+	logger.Info("announce the server on the LAN", "addr", a.conn.LocalAddr().String())
+	for {
+		buf := make([]byte, 1024)
+		size, remoteAddr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		message, err := jsonsutil.Unmarshal[network.Message](buf[:size])
+		if err != nil || message.AsCMD() != network.Discover {
+			continue
+		}
+
+		logger.Debug("announce the server", "addr", remoteAddr.String())
+		beacon := a.beacon()
+		beacon.ID = a.id
+		bytes, _ := jsonsutil.Marshal(network.NewMessage(network.Announce.String(), beacon))
+		if _, err := a.conn.WriteTo(bytes, remoteAddr); err != nil {
+			logger.Warn("fail to announce the server", "addr", remoteAddr.String(), "err", err)
+		}
+	}
+}
+
+// Shutdown stops the announcer
+func (a *Announcer) Shutdown() {
+	if err := a.conn.Close(); err != nil {
+		logger.Warn("fail to close the discovery connection", "err", err)
+	}
+}
+
+// Discover broadcasts a discovery request on the LAN (and the local machine)
+// and it collects the servers which answer before the {timeout}, a server
+// reachable from several addresses is kept once with the fastest one
+func Discover(port int, timeout time.Duration) ([]Server, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, fmt.Errorf("fail to listen on udp: %w", err)
+	}
+	defer conn.Close()
+
+	bytes, _ := jsonsutil.Marshal(network.NewSimpleMessage(network.Discover.String()))
+	start := time.Now()
+	for _, ip := range []net.IP{net.IPv4bcast, net.IPv4(127, 0, 0, 1)} {
+		if _, err := conn.WriteTo(bytes, &net.UDPAddr{IP: ip, Port: port}); err != nil {
+			logger.Warn("fail to send the discovery request", "ip", ip.String(), "err", err)
+		}
+	}
+
+	servers := make(map[string]Server)
+	if err := conn.SetReadDeadline(start.Add(timeout)); err != nil {
+		return nil, err
+	}
+	for {
+		buf := make([]byte, 1024)
+		size, remoteAddr, err := conn.ReadFrom(buf)
+		if err != nil {
+			break // timeout
+		}
+
+		message, err := jsonsutil.Unmarshal[network.Message](buf[:size])
+		if err != nil || message.AsCMD() != network.Announce {
+			continue
+		}
+		beacon, err := network.DecodeValue[Beacon](message)
+		if err != nil {
+			continue
+		}
+
+		host, _, _ := net.SplitHostPort(remoteAddr.String())
+		server := Server{Beacon: beacon, Addr: net.JoinHostPort(host, strconv.Itoa(beacon.Port)), Ping: time.Since(start)}
+		if _, ok := servers[beacon.ID]; !ok {
+			servers[beacon.ID] = server
+		}
+	}
+
+	result := make([]Server, 0, len(servers))
+	for _, server := range servers {
+		result = append(result, server)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Ping < result[j].Ping })
+
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package discovery
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/network"
+)
+
+func TestDiscover(t *testing.T) {
+	beacon := Beacon{Name: "test", Version: "v1", Port: 3000, Rules: network.Rules{Score: 11, SetScore: 3, SetGapWScore: 2}, FreeSlots: 1}
+	announcer, err := NewAnnouncer(0, func() Beacon { return beacon })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer announcer.Shutdown()
+	go announcer.Serve()
+
+	servers, err := Discover(announcer.LocalAddr().(*net.UDPAddr).Port, 500*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 1 {
+		t.Fatalf("got %d servers, want 1", len(servers))
+	}
+	found := servers[0]
+	if found.ID == "" || found.Name != beacon.Name || found.Rules != beacon.Rules || found.FreeSlots != 1 {
+		t.Errorf("unexpected beacon: %+v", found.Beacon)
+	}
+	if _, port, _ := net.SplitHostPort(found.Addr); port != "3000" || found.Ping <= 0 {
+		t.Errorf("unexpected server: %+v", servers[0])
+	}
+}
+
+func TestDiscoverWithoutServer(t *testing.T) {
+	announcer, err := NewAnnouncer(0, func() Beacon { return Beacon{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := announcer.LocalAddr().(*net.UDPAddr).Port
+	announcer.Shutdown()
+
+	servers, err := Discover(port, 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 0 {
+		t.Errorf("got %d servers, want 0", len(servers))
+	}
+}
@@ -1,12 +1,18 @@
 package network
 
 import (
-	"log"
+	"net"
 	"sync"
+
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/metrics"
 )
 
+var hubLogger = logging.For(logging.Hub)
+
 type Subscriber struct {
 	NetworkAddr string
+	Addr        net.Addr
 	Publish     chan Message
 	Shutdown    chan int
 }
@@ -16,7 +22,10 @@ type Hub struct {
 	Broadcast   chan Message
 	Register    chan *Subscriber
 	Unregister  chan string
-	mu          sync.Mutex
+	// Done stops the {Run} loop, the messages sent to the hub afterwards are dropped
+	Done     chan struct{}
+	stopping sync.Once
+	mu       sync.Mutex
 }
 
 func NewHub() *Hub {
@@ -24,39 +33,77 @@ func NewHub() *Hub {
 		Broadcast:   make(chan Message),
 		Register:    make(chan *Subscriber),
 		Unregister:  make(chan string),
+		Done:        make(chan struct{}),
 		Subscribers: make(map[string]*Subscriber),
 	}
 }
 
+// Get returns the {networkAddr} subscriber if it is registered
+func (h *Hub) Get(networkAddr string) (*Subscriber, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subscriber, ok := h.Subscribers[networkAddr]
+	return subscriber, ok
+}
+
+// Shutdown unregisters all the subscribers
 func (h *Hub) Shutdown() {
-	for subscriber := range h.Subscribers {
-		h.Unregister <- subscriber
+	h.mu.Lock()
+	networkAddrs := make([]string, 0, len(h.Subscribers))
+	for networkAddr := range h.Subscribers {
+		networkAddrs = append(networkAddrs, networkAddr)
+	}
+	h.mu.Unlock()
+
+	for _, networkAddr := range networkAddrs {
+		Post(h, h.Unregister, networkAddr)
+	}
+}
+
+// Stop stops the {Run} loop once
+func (h *Hub) Stop() {
+	h.stopping.Do(func() { close(h.Done) })
+}
+
+// Post sends the {value} to the {ch} channel of the {h} hub, the value is dropped once the hub is stopped
+func Post[T any](h *Hub, ch chan<- T, value T) {
+	select {
+	case ch <- value:
+	case <-h.Done:
 	}
 }
 
 func (h *Hub) Run() {
 	for {
 		select {
+		case <-h.Done:
+			hubLogger.Debug("stop the hub")
+			return
 		case subscriber := <-h.Register:
 			h.mu.Lock()
-			log.Printf("hub: register new subscriber [%s]", subscriber.NetworkAddr)
+			hubLogger.Info("register new subscriber", "addr", subscriber.NetworkAddr)
 			h.Subscribers[subscriber.NetworkAddr] = subscriber
+			metrics.Subscribers.Set(float64(len(h.Subscribers)))
 			h.mu.Unlock()
 		case networkAddr := <-h.Unregister:
 			h.mu.Lock()
 			if subscriber, ok := h.Subscribers[networkAddr]; ok {
-				log.Printf("hub: unregister subscriber [%s]", networkAddr)
+				hubLogger.Info("unregister subscriber", "addr", networkAddr)
 				subscriber.Shutdown <- 1
 				delete(h.Subscribers, networkAddr)
+				metrics.Subscribers.Set(float64(len(h.Subscribers)))
 			}
 			h.mu.Unlock()
 		case message := <-h.Broadcast:
 			for networkAddr, subscriber := range h.Subscribers {
-				log.Printf("hub: publish message to subscriber [%s]", networkAddr)
+				hubLogger.Debug("publish message to subscriber", "addr", networkAddr, "cmd", message.Data.Cmd)
 				select {
 				case subscriber.Publish <- message:
 				default:
+					hubLogger.Warn("drop broadcast, subscriber not ready", "addr", networkAddr, "cmd", message.Data.Cmd)
+					metrics.DroppedBroadcasts.Inc()
 					delete(h.Subscribers, networkAddr)
+					metrics.Subscribers.Set(float64(len(h.Subscribers)))
 				}
 			}
 		}
@@ -0,0 +1,35 @@
+package network
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHubStop(t *testing.T) {
+	h := NewHub()
+	stopped := make(chan struct{})
+	go func() {
+		h.Run()
+		close(stopped)
+	}()
+
+	subscriber := &Subscriber{NetworkAddr: "127.0.0.1:3000", Publish: make(chan Message, 1), Shutdown: make(chan int, 1)}
+	Post(h, h.Register, subscriber)
+	h.Shutdown()
+	select {
+	case <-subscriber.Shutdown:
+	case <-time.After(time.Second):
+		t.Fatal("the subscriber should be unregistered on shutdown")
+	}
+
+	h.Stop()
+	h.Stop()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run should return once the hub is stopped")
+	}
+
+	// the messages sent to a stopped hub are dropped
+	Post(h, h.Broadcast, NewSimpleMessage(Ping.String()))
+}
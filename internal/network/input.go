@@ -0,0 +1,14 @@
+package network
+
+// Rollback represents the settings of the rollback netcode chosen by the host
+type Rollback struct {
+	// InputDelay is the number of frames the inputs of both players are delayed
+	InputDelay int `json:"inputDelay"`
+}
+
+// InputFrames represents the [Input] message, the inputs of a player from the {Frame} frame
+// (the last inputs are resent on each frame to cover the lost messages)
+type InputFrames struct {
+	Frame  int     `json:"frame"`
+	Inputs []uint8 `json:"inputs"`
+}
@@ -0,0 +1,60 @@
+package network
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+const (
+	// RulesMaxScore is the max number of points of a match
+	RulesMaxScore = 99
+	// ColorMinDistance is the min distance (RGB space) between the colour of a paddle
+	// and the reserved colours of the table, a closer paddle can not be seen
+	ColorMinDistance = 64
+)
+
+// Profile represents the choices of a player in the lobby
+type Profile struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// Sanitize removes the non printable characters and truncates the fields of the profile,
+// the colour is dropped if it is not a #rrggbb colour or if it is close to one of the {reserved} colours
+func (p Profile) Sanitize(reserved ...color.Color) Profile {
+	// a longer colour is not truncated to a valid one
+	profile := Profile{
+		Name:  sanitize(p.Name, ChatNameMaxLen),
+		Color: sanitize(p.Color, len("#rrggbb")+1),
+	}
+	var r, g, b uint8
+	if n, err := fmt.Sscanf(profile.Color, "#%02x%02x%02x", &r, &g, &b); err != nil || n != 3 ||
+		len(profile.Color) != len("#rrggbb") || ColorClose(color.RGBA{r, g, b, 255}, reserved...) {
+		profile.Color = ""
+	}
+	return profile
+}
+
+// ColorClose returns true if the {c} colour is closer than {ColorMinDistance} to one of the {reserved} colours
+func ColorClose(c color.Color, reserved ...color.Color) bool {
+	r1, g1, b1, _ := c.RGBA()
+	for _, other := range reserved {
+		if other == nil {
+			continue
+		}
+		r2, g2, b2, _ := other.RGBA()
+		dr, dg, db := float64(r1>>8)-float64(r2>>8), float64(g1>>8)-float64(g2>>8), float64(b1>>8)-float64(b2>>8)
+		if math.Sqrt(dr*dr+dg*dg+db*db) < ColorMinDistance {
+			return true
+		}
+	}
+	return false
+}
+
+// LobbyState represents the [Lobby] message sent by a side before the game,
+// only the host sends the rules of the match
+type LobbyState struct {
+	Profile Profile `json:"profile"`
+	Rules   *Rules  `json:"rules,omitempty"`
+}
@@ -0,0 +1,38 @@
+package network
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestProfileSanitize(t *testing.T) {
+	table, background := color.RGBA{0, 0, 0, 255}, color.RGBA{40, 40, 60, 255}
+
+	for _, tt := range []struct {
+		color string
+		want  string
+	}{
+		{"#ff0000", "#ff0000"},
+		{" #00ff00\t", "#00ff00"},
+		{"#000000", ""},
+		{"#101010", ""},
+		{"#2a2a40", ""},
+		{"red", ""},
+		{"#ff00", ""},
+		{"#ff0000ff", ""},
+	} {
+		if got := (Profile{Name: "Player\tL", Color: tt.color}).Sanitize(table, background); got.Color != tt.want || got.Name != "PlayerL" {
+			t.Errorf("%q: got %+v, want the colour %q", tt.color, got, tt.want)
+		}
+	}
+}
+
+func TestColorClose(t *testing.T) {
+	table := color.RGBA{0, 100, 0, 255}
+	if !ColorClose(color.RGBA{10, 110, 10, 255}, nil, table) {
+		t.Error("expected a colour close to the table")
+	}
+	if ColorClose(color.RGBA{200, 100, 0, 255}, table) || ColorClose(table) {
+		t.Error("expected a colour far from the table")
+	}
+}
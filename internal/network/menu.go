@@ -0,0 +1,19 @@
+package network
+
+// MenuItemMaxLen is the max length of the item of a pause menu
+const MenuItemMaxLen = 16
+
+// MenuState represents the [Menu] message sent by a side while the game is paused,
+// the name of the player who paused the game and the item focused on its menu
+type MenuState struct {
+	PausedBy string `json:"pausedBy,omitempty"`
+	Item     string `json:"item"`
+}
+
+// Sanitize removes the non printable characters and truncates the fields of the menu state
+func (s MenuState) Sanitize() MenuState {
+	return MenuState{
+		PausedBy: sanitize(s.PausedBy, ChatNameMaxLen),
+		Item:     sanitize(s.Item, MenuItemMaxLen),
+	}
+}
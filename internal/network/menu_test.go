@@ -0,0 +1,17 @@
+package network
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMenuStateSanitize(t *testing.T) {
+	s := MenuState{PausedBy: " Player\tL ", Item: strings.Repeat("x", MenuItemMaxLen+1)}.Sanitize()
+
+	if s.PausedBy != "PlayerL" {
+		t.Errorf("paused by: got %q", s.PausedBy)
+	}
+	if len(s.Item) != MenuItemMaxLen {
+		t.Errorf("item: got %q", s.Item)
+	}
+}
@@ -1,5 +1,7 @@
 package network
 
+import "github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+
 type Message struct {
 	NetworkAddr string `json:"networkAddr"`
 	Data        Data   `json:"data"`
@@ -27,24 +29,65 @@ func (m Message) WithAddr(v string) Message {
 	return m
 }
 
+// DecodeValue decodes the generic value of the {m} message into the {T} type
+func DecodeValue[T any](m Message) (T, error) {
+	bytes, err := jsonsutil.Marshal(m.Data.Value)
+	if err != nil {
+		var t T
+		return t, err
+	}
+	return jsonsutil.Unmarshal[T](bytes)
+}
+
 type CMD int
 
 const (
-	Notify CMD = iota
+	Announce CMD = iota
+	Chat
+	Discover
+	Forfeit
+	Input
+	Lobby
+	Menu
+	Migrate
+	Notify
+	Pause
 	Ping
 	PingAll
 	Pong
 	Ready
+	Resume
+	Seed
+	Session
 	Shutdown
 	Subscribe
+	Swap
 	UpdateCurrentState
 	UpdatePaddleY
 )
 
 func (c CMD) String() string {
 	switch c {
+	case Announce:
+		return "Announce"
+	case Chat:
+		return "Chat"
+	case Discover:
+		return "Discover"
+	case Forfeit:
+		return "Forfeit"
+	case Input:
+		return "Input"
+	case Lobby:
+		return "Lobby"
+	case Menu:
+		return "Menu"
+	case Migrate:
+		return "Migrate"
 	case Notify:
 		return "Notify"
+	case Pause:
+		return "Pause"
 	case Ping:
 		return "Ping"
 	case PingAll:
@@ -53,10 +96,18 @@ func (c CMD) String() string {
 		return "Pong"
 	case Ready:
 		return "Ready"
+	case Resume:
+		return "Resume"
+	case Seed:
+		return "Seed"
+	case Session:
+		return "Session"
 	case Shutdown:
 		return "Shutdown"
 	case Subscribe:
 		return "Subscribe"
+	case Swap:
+		return "Swap"
 	case UpdateCurrentState:
 		return "UpdateCurrentState"
 	case UpdatePaddleY:
@@ -68,8 +119,26 @@ func (c CMD) String() string {
 
 func toCMD(v string) CMD {
 	switch v {
+	case "Announce":
+		return Announce
+	case "Chat":
+		return Chat
+	case "Discover":
+		return Discover
+	case "Forfeit":
+		return Forfeit
+	case "Input":
+		return Input
+	case "Lobby":
+		return Lobby
+	case "Menu":
+		return Menu
+	case "Migrate":
+		return Migrate
 	case "Notify":
 		return Notify
+	case "Pause":
+		return Pause
 	case "Ping":
 		return Ping
 	case "PingAll":
@@ -78,10 +147,18 @@ func toCMD(v string) CMD {
 		return Pong
 	case "Ready":
 		return Ready
+	case "Resume":
+		return Resume
+	case "Seed":
+		return Seed
+	case "Session":
+		return Session
 	case "Shutdown":
 		return Shutdown
 	case "Subscribe":
 		return Subscribe
+	case "Swap":
+		return Swap
 	case "UpdateCurrentState":
 		return UpdateCurrentState
 	case "UpdatePaddleY":
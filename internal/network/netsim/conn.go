@@ -0,0 +1,183 @@
+package netsim
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/network"
+)
+
+var logger = logging.For(logging.NetSim)
+
+// receivedBuffer is the number of messages read by the wrapped connection and not forwarded yet
+const receivedBuffer = 64
+
+// Conditions represents the network conditions to simulate
+type Conditions struct {
+	// Latency is the delay added to every message
+	Latency time.Duration
+	// Jitter is the maximum random delay added to (or removed from) the latency
+	Jitter time.Duration
+	// Loss is the probability [0..1] to drop a message
+	Loss float64
+	// Duplicate is the probability [0..1] to deliver a message twice
+	Duplicate float64
+	// Reorder is the probability [0..1] to deliver a message after the next ones
+	Reorder float64
+	// Seed is the seed of the random source (0 to use a random seed)
+	Seed uint64
+}
+
+// Enabled returns true if at least one condition is set
+func (c Conditions) Enabled() bool {
+	return c.Latency > 0 || c.Jitter > 0 || c.Loss > 0 || c.Duplicate > 0 || c.Reorder > 0
+}
+
+// Validate checks that the probabilities and durations are valid
+func (c Conditions) Validate() error {
+	for name, p := range map[string]float64{"loss": c.Loss, "duplicate": c.Duplicate, "reorder": c.Reorder} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("netsim %s probability must be between 0 and 1 (%v)", name, p)
+		}
+	}
+	if c.Latency < 0 || c.Jitter < 0 {
+		return fmt.Errorf("netsim latency and jitter must be positive (%v, %v)", c.Latency, c.Jitter)
+	}
+	return nil
+}
+
+func (c Conditions) String() string {
+	return fmt.Sprintf("latency=%v jitter=%v loss=%0.2f duplicate=%0.2f reorder=%0.2f",
+		c.Latency, c.Jitter, c.Loss, c.Duplicate, c.Reorder)
+}
+
+// Conn is a {network.Conn} which applies the network {Conditions}
+// to the messages sent and received by the wrapped connection
+type Conn struct {
+	conn       network.Conn
+	conditions Conditions
+
+	random *rand.Rand
+	mu     sync.Mutex
+
+	// done is closed on shutdown, it stops the forwarding and the delayed deliveries
+	done     chan struct{}
+	shutdown sync.Once
+}
+
+// Wrap builds a new {Conn} type which wraps the {conn} connection
+func Wrap(conn network.Conn, conditions Conditions) *Conn {
+	seed := conditions.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	logger.Info("simulate network conditions", "conditions", conditions.String(), "seed", seed)
+	return &Conn{
+		conn:       conn,
+		conditions: conditions,
+		random:     rand.New(rand.NewPCG(seed, seed)),
+		done:       make(chan struct{}),
+	}
+}
+
+// ListenAndServe listens messages from the wrapped connection
+// and serves them to the application according to the conditions
+func (c *Conn) ListenAndServe(messages chan<- network.Message) {
+	// the wrapped connection never closes the channel, the buffer absorbs
+	// the messages it still reads while it shuts down
+	received := make(chan network.Message, receivedBuffer)
+	go c.conn.ListenAndServe(received)
+
+	serve := func(msg network.Message) {
+		if c.closed() {
+			return
+		}
+		select {
+		case messages <- msg:
+		case <-c.done:
+		}
+	}
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-received:
+			// local messages (ex: [PingAll] from the ticker) do not go through the network
+			if message.NetworkAddr == "" {
+				serve(message)
+				continue
+			}
+			c.deliver(message, serve)
+		}
+	}
+}
+
+// Send sends the {msg} to the wrapped connection according to the conditions
+func (c *Conn) Send(msg network.Message) {
+	c.deliver(msg, func(msg network.Message) {
+		if !c.closed() {
+			c.conn.Send(msg)
+		}
+	})
+}
+
+// closed returns true once the connection is shut down
+func (c *Conn) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stops the delivery of the messages and it shutdowns the wrapped connection
+func (c *Conn) Shutdown() {
+	c.shutdown.Do(func() { close(c.done) })
+	c.conn.Shutdown()
+}
+
+// deliver applies the conditions before calling {deliver} with the {msg}
+func (c *Conn) deliver(msg network.Message, deliver func(network.Message)) {
+	c.mu.Lock()
+	lost := c.random.Float64() < c.conditions.Loss
+	duplicated := c.random.Float64() < c.conditions.Duplicate
+	delays := []time.Duration{c.delay()}
+	if duplicated {
+		delays = append(delays, c.delay())
+	}
+	c.mu.Unlock()
+
+	if lost {
+		logger.Debug("drop message", "addr", msg.NetworkAddr, "cmd", msg.Data.Cmd)
+		return
+	}
+	if duplicated {
+		logger.Debug("duplicate message", "addr", msg.NetworkAddr, "cmd", msg.Data.Cmd)
+	}
+
+	for _, delay := range delays {
+		if delay <= 0 {
+			deliver(msg)
+			continue
+		}
+		time.AfterFunc(delay, func() { deliver(msg) })
+	}
+}
+
+// delay computes the delay of a message (latency, jitter and reordering)
+func (c *Conn) delay() time.Duration {
+	delay := c.conditions.Latency
+	if c.conditions.Jitter > 0 {
+		delay += time.Duration(c.random.Int64N(int64(2*c.conditions.Jitter)+1)) - c.conditions.Jitter
+	}
+	if c.random.Float64() < c.conditions.Reorder {
+		// hold the message long enough to be overtaken by the next ones
+		delay += c.conditions.Latency + c.conditions.Jitter + 50*time.Millisecond
+	}
+	return max(delay, 0)
+}
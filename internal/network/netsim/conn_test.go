@@ -0,0 +1,134 @@
+package netsim
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/network"
+)
+
+// fakeConn is a {network.Conn} which records the messages sent and serves the messages pushed by the test
+type fakeConn struct {
+	incoming chan network.Message
+	sent     chan network.Message
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{incoming: make(chan network.Message), sent: make(chan network.Message, 4096), stopped: make(chan struct{})}
+}
+
+func (f *fakeConn) ListenAndServe(messages chan<- network.Message) {
+	for {
+		select {
+		case <-f.stopped:
+			return
+		case message := <-f.incoming:
+			messages <- message
+		}
+	}
+}
+
+func (f *fakeConn) Send(msg network.Message) {
+	f.sent <- msg
+}
+
+func (f *fakeConn) Shutdown() {
+	f.once.Do(func() { close(f.stopped) })
+}
+
+// sendAll sends {nb} messages through a connection with the {conditions}
+// and it returns the indexes of the messages delivered at once
+func sendAll(conditions Conditions, nb int) []int {
+	fake := newFakeConn()
+	conn := Wrap(fake, conditions)
+	for i := range nb {
+		conn.Send(network.NewMessage(network.Ping.String(), i).WithAddr("127.0.0.1:3000"))
+	}
+	close(fake.sent)
+
+	var delivered []int
+	for msg := range fake.sent {
+		delivered = append(delivered, msg.Data.Value.(int))
+	}
+	return delivered
+}
+
+func TestLoss(t *testing.T) {
+	conditions := Conditions{Loss: 0.3, Seed: 42}
+	delivered := sendAll(conditions, 1000)
+	if len(delivered) < 600 || len(delivered) > 800 {
+		t.Fatalf("got %d messages delivered, expected about 700", len(delivered))
+	}
+
+	// the same seed drops the same messages
+	again := sendAll(conditions, 1000)
+	if len(again) != len(delivered) {
+		t.Fatalf("got %d then %d messages delivered with the same seed", len(delivered), len(again))
+	}
+	for i := range delivered {
+		if delivered[i] != again[i] {
+			t.Fatalf("got the message %d then %d with the same seed", delivered[i], again[i])
+		}
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	delivered := sendAll(Conditions{Duplicate: 1, Seed: 42}, 10)
+	if len(delivered) != 20 {
+		t.Fatalf("got %d messages delivered, expected each message twice", len(delivered))
+	}
+	for i, value := range delivered {
+		if value != i/2 {
+			t.Fatalf("got %v, expected each message twice in order", delivered)
+		}
+	}
+}
+
+func TestDelay(t *testing.T) {
+	fake := newFakeConn()
+	conn := Wrap(fake, Conditions{Latency: 50 * time.Millisecond, Seed: 42})
+
+	start := time.Now()
+	conn.Send(network.NewMessage(network.Ping.String(), 1).WithAddr("127.0.0.1:3000"))
+	select {
+	case <-fake.sent:
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("got the message after %v, expected the latency", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	fake := newFakeConn()
+	conn := Wrap(fake, Conditions{Latency: 50 * time.Millisecond, Seed: 42})
+
+	messages := make(chan network.Message)
+	stopped := make(chan struct{})
+	go func() {
+		conn.ListenAndServe(messages)
+		close(stopped)
+	}()
+
+	// a message delayed by the latency is not delivered after the shutdown
+	fake.incoming <- network.NewMessage(network.Ping.String(), 1).WithAddr("127.0.0.1:3000")
+	conn.Send(network.NewMessage(network.Ping.String(), 2).WithAddr("127.0.0.1:3000"))
+	conn.Shutdown()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("ListenAndServe should return on shutdown")
+	}
+	select {
+	case msg := <-messages:
+		t.Errorf("got %+v after the shutdown", msg)
+	case msg := <-fake.sent:
+		t.Errorf("sent %+v after the shutdown", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
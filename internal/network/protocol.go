@@ -0,0 +1,119 @@
+package network
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// ProtocolVersion is the version of the messages exchanged by this build,
+	// it changes on each incompatible change of the protocol
+	ProtocolVersion = 1
+	// MinProtocolVersion is the oldest version of the protocol still supported
+	MinProtocolVersion = 1
+)
+
+const (
+	// CapabilityCodecJSON encodes the messages in JSON
+	CapabilityCodecJSON = "codec:json"
+	// CapabilityRules lets the server impose its rules (score, sets) to the client
+	CapabilityRules = "rules"
+	// CapabilityLobby lets both sides share their profile and the host its rules before the game
+	CapabilityLobby = "lobby"
+	// CapabilityMenu lets both sides share the pause menu and a player forfeit the match
+	CapabilityMenu = "menu"
+	// CapabilityRoles lets the players swap sides, request a pause and the client take over the host
+	CapabilityRoles = "roles"
+	// CapabilityRollback lets the players exchange their inputs on each frame to play with the rollback netcode
+	CapabilityRollback = "rollback"
+	// CapabilitySeed lets the host share the seed of the pseudo-random source of each match
+	CapabilitySeed = "seed"
+	// CapabilitySpectators lets the server accept spectators (not supported yet)
+	CapabilitySpectators = "spectators"
+)
+
+// Capabilities are the optional features supported by this build
+var Capabilities = []string{CapabilityCodecJSON, CapabilityRules, CapabilityLobby, CapabilityMenu, CapabilityRoles, CapabilityRollback, CapabilitySeed}
+
+const (
+	Accepted = "accepted"
+	Refused  = "refused"
+)
+
+// SwapRequest is the value of the [Swap] message which requests to swap the sides,
+// the host answers with its new side once both sides agree
+const SwapRequest = "request"
+
+// Hello represents the [Subscribe] handshake sent by a client to the server
+type Hello struct {
+	Protocol     int      `json:"protocol"`
+	Capabilities []string `json:"capabilities"`
+	Token        string   `json:"token,omitempty"`
+}
+
+// NewHello builds the handshake of this build with the {token} session to resume
+func NewHello(token string) Hello {
+	return Hello{Protocol: ProtocolVersion, Capabilities: Capabilities, Token: token}
+}
+
+// Welcome represents the [Subscribe] answer of the server to the handshake
+type Welcome struct {
+	Status       string   `json:"status"`
+	Reason       string   `json:"reason,omitempty"`
+	Protocol     int      `json:"protocol"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	Rules        *Rules   `json:"rules,omitempty"`
+	// Rollback is set if the host plays the match with the rollback netcode
+	Rollback *Rollback `json:"rollback,omitempty"`
+	// Seed is the seed of the next match (a string to keep the 64 bits in JSON)
+	Seed uint64 `json:"seed,string,omitempty"`
+}
+
+// NewWelcome builds the answer which accepts a client with the negotiated {capabilities}
+func NewWelcome(capabilities []string, rules *Rules, rollback *Rollback, seed uint64) Welcome {
+	return Welcome{Status: Accepted, Protocol: ProtocolVersion, Capabilities: capabilities, Rules: rules, Rollback: rollback, Seed: seed}
+}
+
+// NewRefusal builds the answer which refuses a client for the {reason}
+func NewRefusal(reason string) Welcome {
+	return Welcome{Status: Refused, Reason: reason, Protocol: ProtocolVersion}
+}
+
+// Rules represents the rules of the game hosted by a server
+type Rules struct {
+	Score        int `json:"score"`
+	SetScore     int `json:"setScore"`
+	SetGapWScore int `json:"setGapWScore"`
+}
+
+func (r Rules) String() string {
+	return fmt.Sprintf("%d/%d+%d", r.Score, r.SetScore, r.SetGapWScore)
+}
+
+// Valid returns true if a match can be won with the rules
+func (r Rules) Valid() bool {
+	return r.SetGapWScore >= 1 && r.SetGapWScore <= r.SetScore &&
+		r.SetScore >= 1 && r.SetScore <= r.Score && r.Score <= RulesMaxScore
+}
+
+// Negotiate checks that the {hello} handshake is compatible with this build
+// and it returns the capabilities supported by both sides
+func Negotiate(hello Hello) ([]string, error) {
+	if hello.Protocol < MinProtocolVersion || hello.Protocol > ProtocolVersion {
+		return nil, fmt.Errorf("protocol v%d not supported (server v%d)", hello.Protocol, ProtocolVersion)
+	}
+
+	capabilities := make([]string, 0, len(Capabilities))
+	for _, capability := range Capabilities {
+		if slices.Contains(hello.Capabilities, capability) {
+			capabilities = append(capabilities, capability)
+		}
+	}
+
+	if !slices.ContainsFunc(capabilities, func(c string) bool { return strings.HasPrefix(c, "codec:") }) {
+		return nil, fmt.Errorf("no common codec (server %s)", CapabilityCodecJSON)
+	}
+
+	return capabilities, nil
+}
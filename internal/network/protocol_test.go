@@ -0,0 +1,71 @@
+package network
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+)
+
+func TestNegotiate(t *testing.T) {
+	capabilities, err := Negotiate(Hello{
+		Protocol:     ProtocolVersion,
+		Capabilities: []string{CapabilityRules, CapabilitySpectators, CapabilityCodecJSON, "codec:msgpack"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(capabilities, []string{CapabilityCodecJSON, CapabilityRules}) {
+		t.Errorf("got %v, want the capabilities supported by both sides", capabilities)
+	}
+}
+
+func TestNegotiateWithIncompatibleProtocol(t *testing.T) {
+	for _, protocol := range []int{MinProtocolVersion - 1, ProtocolVersion + 1} {
+		if _, err := Negotiate(Hello{Protocol: protocol, Capabilities: Capabilities}); err == nil {
+			t.Errorf("protocol v%d: expected an error", protocol)
+		}
+	}
+}
+
+func TestNegotiateWithoutCommonCodec(t *testing.T) {
+	if _, err := Negotiate(Hello{Protocol: ProtocolVersion, Capabilities: []string{"codec:msgpack", CapabilityRules}}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestRulesValid(t *testing.T) {
+	for _, test := range []struct {
+		rules Rules
+		valid bool
+	}{
+		{Rules{Score: 11, SetScore: 3, SetGapWScore: 2}, true},
+		{Rules{Score: 1, SetScore: 1, SetGapWScore: 1}, true},
+		{Rules{Score: 11, SetScore: 0, SetGapWScore: 0}, false},
+		{Rules{Score: 11, SetScore: 3, SetGapWScore: 4}, false},
+		{Rules{Score: 3, SetScore: 5, SetGapWScore: 2}, false},
+		{Rules{Score: RulesMaxScore + 1, SetScore: 3, SetGapWScore: 2}, false},
+	} {
+		if valid := test.rules.Valid(); valid != test.valid {
+			t.Errorf("%s: got %t, want %t", test.rules, valid, test.valid)
+		}
+	}
+}
+
+func TestWelcomeKeepsTheSeed(t *testing.T) {
+	bytes, err := jsonsutil.Marshal(NewMessage(Subscribe.String(), NewWelcome(Capabilities, nil, nil, math.MaxUint64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := jsonsutil.Unmarshal[Message](bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	welcome, err := DecodeValue[Welcome](message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if welcome.Seed != math.MaxUint64 {
+		t.Errorf("got seed %d, want %d", welcome.Seed, uint64(math.MaxUint64))
+	}
+}
@@ -0,0 +1,244 @@
+package rollback
+
+import "fmt"
+
+const (
+	// MaxPrediction is the max number of frames simulated ahead of the last confirmed frame,
+	// the session waits for the remote inputs beyond
+	MaxPrediction = 8
+	// MaxInputDelay is the max number of frames the local inputs can be delayed
+	MaxInputDelay = 10
+	// Redundancy is the number of last local inputs sent on each frame to cover the lost messages
+	// (and the first frames of the input delay)
+	Redundancy = MaxInputDelay + MaxPrediction
+	// maxAhead is the max number of frames the remote side can be ahead (both delays and its prediction)
+	maxAhead = 2*MaxInputDelay + MaxPrediction
+)
+
+// Input represents the buttons pressed by a player during a frame
+type Input uint8
+
+const (
+	InputUp Input = 1 << iota
+	InputDown
+)
+
+// Valid returns true if the input only contains known buttons
+func (i Input) Valid() bool {
+	return i&^(InputUp|InputDown) == 0
+}
+
+// Simulation is a deterministic simulation which can be saved, restored and advanced frame by frame
+type Simulation[S any] interface {
+	// Save returns the current state of the simulation
+	Save() S
+	// Load restores the {state} of the simulation
+	Load(state S)
+	// Step advances the simulation of one frame with the inputs of both players
+	Step(local, remote Input)
+}
+
+// Stats represents the rollbacks done by a session
+type Stats struct {
+	// Rollbacks is the number of mispredictions which rolled the simulation back
+	Rollbacks int
+	// Resimulated is the number of frames simulated again after a rollback
+	Resimulated int
+	// MaxDepth is the max number of frames rolled back at once
+	MaxDepth int
+}
+
+// frame represents the state of the simulation before a frame and the remote input it was simulated with
+type frame[S any] struct {
+	state     S
+	predicted Input
+}
+
+// Session runs a {Simulation} with the local inputs delayed of {delay} frames,
+// it predicts the missing remote inputs (the last known input is repeated) and on a
+// misprediction it rolls the simulation back to the mispredicted frame and simulates it again
+type Session[S any] struct {
+	sim   Simulation[S]
+	delay int
+
+	// frame is the next frame to simulate
+	frame  int
+	frames map[int]*frame[S]
+	local  map[int]Input
+	remote map[int]Input
+
+	// confirmed is the last frame whose remote input (and every previous one) is known
+	confirmed int
+	// mispredicted is the first simulated frame whose prediction was wrong (-1 if none)
+	mispredicted int
+
+	stats Stats
+}
+
+// New builds a session which runs the {sim} simulation with the local inputs delayed of {delay} frames
+func New[S any](sim Simulation[S], delay int) (*Session[S], error) {
+	if delay < 0 || delay > MaxInputDelay {
+		return nil, fmt.Errorf("input delay must be between 0 and %d frames (%d)", MaxInputDelay, delay)
+	}
+
+	s := &Session[S]{
+		sim:          sim,
+		delay:        delay,
+		frames:       map[int]*frame[S]{},
+		local:        map[int]Input{},
+		remote:       map[int]Input{},
+		confirmed:    -1,
+		mispredicted: -1,
+	}
+	// the first frames are played without input on both sides
+	for f := 0; f < delay; f++ {
+		s.local[f] = 0
+	}
+	return s, nil
+}
+
+// Frame returns the next frame to simulate
+func (s *Session[S]) Frame() int {
+	return s.frame
+}
+
+// Confirmed returns the last frame whose inputs of both players are known
+func (s *Session[S]) Confirmed() int {
+	return min(s.confirmed, s.frame-1)
+}
+
+// Delay returns the number of frames the local inputs are delayed
+func (s *Session[S]) Delay() int {
+	return s.delay
+}
+
+// Stats returns the rollbacks done by the session
+func (s *Session[S]) Stats() Stats {
+	return s.stats
+}
+
+// ConfirmedState returns the state of the simulation after the last confirmed frame,
+// it is up to date once the frame is advanced
+func (s *Session[S]) ConfirmedState() (S, bool) {
+	next := s.Confirmed() + 1
+	if next == 0 {
+		var state S
+		return state, false
+	}
+	if next == s.frame {
+		return s.sim.Save(), true
+	}
+	return s.frames[next].state, true
+}
+
+// Stalled returns true if the session can not predict more frames without the remote inputs
+func (s *Session[S]) Stalled() bool {
+	return s.frame-s.confirmed > MaxPrediction
+}
+
+// AddLocalInput schedules the local {input} sampled now and it returns
+// the frame it is played on (which must be sent to the remote side)
+func (s *Session[S]) AddLocalInput(input Input) int {
+	f := s.frame + s.delay
+	if _, ok := s.local[f]; !ok {
+		s.local[f] = input
+	}
+	return f
+}
+
+// LocalInputs returns the last {Redundancy} local inputs scheduled and the frame of the first one
+// (a player resends them on each frame, after {AddLocalInput}, to cover the lost messages)
+func (s *Session[S]) LocalInputs() (int, []Input) {
+	last := s.frame + s.delay
+	from := max(last-Redundancy+1, 0)
+	inputs := make([]Input, 0, last-from+1)
+	for f := from; f <= last; f++ {
+		inputs = append(inputs, s.local[f])
+	}
+	return from, inputs
+}
+
+// AddRemoteInput records the remote {input} played on the {f} frame,
+// it returns false if the input is already known (duplicated or resent) or too far ahead
+func (s *Session[S]) AddRemoteInput(f int, input Input) bool {
+	if _, ok := s.remote[f]; ok || f <= s.confirmed || f > s.frame+maxAhead {
+		return false
+	}
+	s.remote[f] = input
+
+	if simulated, ok := s.frames[f]; ok && simulated.predicted != input {
+		if s.mispredicted == -1 || f < s.mispredicted {
+			s.mispredicted = f
+		}
+	}
+	for {
+		if _, ok := s.remote[s.confirmed+1]; !ok {
+			break
+		}
+		s.confirmed++
+	}
+	return true
+}
+
+// Advance rolls the simulation back if a prediction was wrong and it simulates the next frame,
+// it returns false if the session waits for the remote inputs
+func (s *Session[S]) Advance() bool {
+	if s.mispredicted != -1 {
+		depth := s.frame - s.mispredicted
+		s.sim.Load(s.frames[s.mispredicted].state)
+		for f := s.mispredicted; f < s.frame; f++ {
+			s.step(f)
+		}
+		s.stats.Rollbacks++
+		s.stats.Resimulated += depth
+		s.stats.MaxDepth = max(s.stats.MaxDepth, depth)
+		s.mispredicted = -1
+	}
+
+	if s.Stalled() {
+		return false
+	}
+
+	s.step(s.frame)
+	s.frame++
+	s.prune()
+	return true
+}
+
+// step simulates the {f} frame with the known inputs (or the predicted remote input)
+func (s *Session[S]) step(f int) {
+	remote, ok := s.remote[f]
+	if !ok {
+		remote = s.predict()
+	}
+	local := s.local[f]
+
+	s.frames[f] = &frame[S]{state: s.sim.Save(), predicted: remote}
+	s.sim.Step(local, remote)
+}
+
+// predict returns the remote input of a frame not received yet (the last confirmed one)
+func (s *Session[S]) predict() Input {
+	return s.remote[s.confirmed]
+}
+
+// prune forgets the frames which can not be rolled back anymore
+func (s *Session[S]) prune() {
+	limit := min(s.confirmed, s.frame-1) - MaxPrediction
+	for f := range s.frames {
+		if f < limit {
+			delete(s.frames, f)
+		}
+	}
+	// the local inputs are kept until they are not resent anymore
+	for f := range s.local {
+		if f < min(limit, s.frame+s.delay-Redundancy) {
+			delete(s.local, f)
+		}
+	}
+	for f := range s.remote {
+		if f < limit {
+			delete(s.remote, f)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package rollback
+
+import "testing"
+
+// counters is a deterministic simulation where each player moves a counter with its inputs
+type counters struct {
+	side  int
+	state [3]int
+}
+
+func (c *counters) Save() [3]int      { return c.state }
+func (c *counters) Load(state [3]int) { c.state = state }
+
+func (c *counters) Step(local, remote Input) {
+	for side, input := range map[int]Input{c.side: local, 1 - c.side: remote} {
+		if input&InputUp != 0 {
+			c.state[side]--
+		}
+		if input&InputDown != 0 {
+			c.state[side]++
+		}
+	}
+	// the hash depends on the order of the frames
+	c.state[2] = c.state[2]*31 + c.state[0] - c.state[1]
+}
+
+// datagram represents the inputs sent to the remote peer
+type datagram struct {
+	at     int
+	frame  int
+	inputs []Input
+}
+
+// play runs two peers exchanging their inputs with the {latency} (in frames), one message of {lossEvery}
+// is lost (0 for none) and it returns the confirmed states of each peer by frame
+func play(t *testing.T, delay, latency, lossEvery, frames int) ([2]map[int][3]int, [2]*Session[[3]int]) {
+	t.Helper()
+
+	var sessions [2]*Session[[3]int]
+	for side := range sessions {
+		session, err := New[[3]int](&counters{side: side}, delay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions[side] = session
+	}
+
+	confirmed := [2]map[int][3]int{{}, {}}
+	var inflight [2][]datagram
+	for tick := 0; tick < frames; tick++ {
+		for side, session := range sessions {
+			// each player changes its input regularly
+			input := Input(0)
+			if tick < frames-latency-2*MaxPrediction {
+				input = []Input{0, InputUp, InputDown, InputUp | InputDown}[(tick/(3+side))%4]
+			}
+			session.AddLocalInput(input)
+			from, inputs := session.LocalInputs()
+			if lossEvery > 0 && tick%lossEvery == 0 {
+				continue
+			}
+			inflight[1-side] = append(inflight[1-side], datagram{at: tick + latency, frame: from, inputs: inputs})
+		}
+		for side, session := range sessions {
+			var pending []datagram
+			for _, d := range inflight[side] {
+				if d.at <= tick {
+					for i, input := range d.inputs {
+						session.AddRemoteInput(d.frame+i, input)
+					}
+				} else {
+					pending = append(pending, d)
+				}
+			}
+			inflight[side] = pending
+
+			if session.Advance() {
+				if state, ok := session.ConfirmedState(); ok {
+					confirmed[side][session.Confirmed()] = state
+				}
+			}
+		}
+	}
+	return confirmed, sessions
+}
+
+func TestSessionsAgree(t *testing.T) {
+	for _, lossEvery := range []int{0, 3} {
+		agree(t, lossEvery)
+	}
+}
+
+// agree checks that both peers confirm the same states
+func agree(t *testing.T, lossEvery int) {
+	t.Helper()
+
+	confirmed, sessions := play(t, 2, 5, lossEvery, 300)
+
+	common := 0
+	for f, state := range confirmed[0] {
+		if other, ok := confirmed[1][f]; ok {
+			common++
+			if state != other {
+				t.Fatalf("frame %d: got %v and %v, want the same state on both peers", f, state, other)
+			}
+		}
+	}
+	if common < 150 {
+		t.Errorf("loss 1/%d: got %d frames confirmed by both peers", lossEvery, common)
+	}
+	for side, session := range sessions {
+		if session.Stats().Rollbacks == 0 {
+			t.Errorf("peer %d: expected rollbacks with a latency above the input delay", side)
+		}
+	}
+}
+
+func TestInputDelayHidesLatency(t *testing.T) {
+	_, sessions := play(t, 3, 2, 0, 300)
+
+	for side, session := range sessions {
+		if stats := session.Stats(); stats.Rollbacks != 0 {
+			t.Errorf("peer %d: got %d rollbacks, want none with an input delay above the latency", side, stats.Rollbacks)
+		}
+	}
+}
+
+func TestPredictAndRollBack(t *testing.T) {
+	sim := &counters{side: 0}
+	session, err := New[[3]int](sim, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the last remote input is repeated on the next frames
+	session.AddRemoteInput(0, InputDown)
+	for range 3 {
+		session.AddLocalInput(0)
+		session.Advance()
+	}
+	if sim.state[1] != 3 {
+		t.Fatalf("got %d, want the remote input predicted on 3 frames", sim.state[1])
+	}
+
+	// the remote player released the button on the frame 1
+	session.AddRemoteInput(1, 0)
+	session.AddLocalInput(0)
+	session.Advance()
+	if sim.state[1] != 1 {
+		t.Errorf("got %d, want the simulation rolled back and simulated again", sim.state[1])
+	}
+	if stats := session.Stats(); stats.Rollbacks != 1 || stats.Resimulated != 2 || stats.MaxDepth != 2 {
+		t.Errorf("got %+v", stats)
+	}
+	if confirmed := session.Confirmed(); confirmed != 1 {
+		t.Errorf("got confirmed frame %d, want 1", confirmed)
+	}
+}
+
+func TestStalledWithoutRemoteInputs(t *testing.T) {
+	session, err := New[[3]int](&counters{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for f := range MaxPrediction {
+		if !session.Advance() {
+			t.Fatalf("frame %d: the session should predict the remote inputs", f)
+		}
+	}
+	if session.Advance() {
+		t.Fatal("the session should wait for the remote inputs")
+	}
+
+	session.AddRemoteInput(0, 0)
+	if !session.Advance() {
+		t.Error("the session should advance once a remote input is received")
+	}
+}
+
+func TestAddRemoteInput(t *testing.T) {
+	session, err := New[[3]int](&counters{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !session.AddRemoteInput(0, InputUp) {
+		t.Error("expected a new input")
+	}
+	if session.AddRemoteInput(0, InputDown) {
+		t.Error("expected the resent input to be ignored")
+	}
+	if session.AddRemoteInput(maxAhead+1, InputDown) {
+		t.Error("expected the input too far ahead to be ignored")
+	}
+}
+
+func TestNewWithInvalidDelay(t *testing.T) {
+	for _, delay := range []int{-1, MaxInputDelay + 1} {
+		if _, err := New[[3]int](&counters{}, delay); err == nil {
+			t.Errorf("delay %d: expected an error", delay)
+		}
+	}
+}
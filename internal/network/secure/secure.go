@@ -0,0 +1,432 @@
+package secure
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+// The server derives the key of its room once (scrypt) from the shared secret and a salt of its own.
+// The client starts an ephemeral X25519 key exchange when it subscribes, its first hello is answered
+// by a cookie bound to its address and by the salt of the room: the client derives the key of the room
+// and it sends the hello again with the cookie, which is accepted once. Both halves are authenticated
+// with the key of the room, the key of the session is derived (HKDF) from the X25519 shared secret,
+// then every datagram is sealed with AES-256-GCM and checked against replays.
+//
+//	hello  [H][cookie 40][client public key 32][mac 32]
+//	cookie [C][timestamp 8][mac 32][salt 16]
+//	key    [K][server public key 32][mac 32]
+//	sealed [E][counter 8][ciphertext + tag]
+const (
+	KindCookie byte = 'C'
+	KindHello  byte = 'H'
+	KindKey    byte = 'K'
+	KindSealed byte = 'E'
+)
+
+const (
+	keySize     = 32
+	macSize     = sha256.Size
+	saltSize    = 16
+	cookieSize  = 8 + macSize
+	counterSize = 8
+	helloSize   = 1 + cookieSize + keySize + macSize
+	replySize   = 1 + keySize + macSize
+
+	// scryptN is the CPU/memory cost of the derivation of the key of the room (a guess of the secret costs as much)
+	scryptN = 1 << 15
+	// cookieMaxAge is the maximum age of the cookie of a hello accepted by the server
+	cookieMaxAge = 30 * time.Second
+	// replayWindow is the number of the last counters remembered to reject the replayed datagrams
+	replayWindow = 64
+	// hellosPerSecond and hellosBurst are the rate limit of the hellos sent by an address
+	hellosPerSecond = 1
+	hellosBurst     = 4
+)
+
+var (
+	// ErrUnauthenticated is returned when a datagram is not authenticated with the shared secret
+	ErrUnauthenticated = errors.New("unauthenticated datagram")
+	// ErrReplayed is returned when a datagram has already been received
+	ErrReplayed = errors.New("replayed datagram")
+	// ErrRateLimited is returned when an address sends too many hellos
+	ErrRateLimited = errors.New("too many hellos")
+)
+
+// Kind returns the kind of the {datagram} (0 if the datagram is a plaintext one)
+func Kind(datagram []byte) byte {
+	if len(datagram) == 0 {
+		return 0
+	}
+	switch datagram[0] {
+	case KindCookie, KindHello, KindKey, KindSealed:
+		return datagram[0]
+	}
+	return 0
+}
+
+// Secret represents the shared secret (password) of a room,
+// the key of the room is derived from it and from the salt of the server
+type Secret []byte
+
+// NewSecret builds the {Secret} of the {password}
+func NewSecret(password string) Secret {
+	return Secret(password)
+}
+
+// derive derives the key of the room from the secret and the {salt}
+func (s Secret) derive(salt []byte) (key, error) {
+	derived, err := scrypt.Key(s, append([]byte("pong/secret/v3:"), salt...), scryptN, 8, 1, keySize)
+	return key(derived), err
+}
+
+// key is the key derived from the secret which authenticates the key exchanges of a room
+type key []byte
+
+func (k key) mac(label string, parts ...[]byte) []byte {
+	h := hmac.New(sha256.New, k)
+	h.Write([]byte(label))
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// Exchange represents the key exchange started by a client
+type Exchange struct {
+	secret Secret
+	// key is the key of the room derived from the {salt} of the server (nil before its first cookie)
+	key     key
+	salt    []byte
+	cookie  []byte
+	private *ecdh.PrivateKey
+}
+
+// NewExchange starts a new key exchange authenticated by the {secret}
+func NewExchange(secret Secret) (*Exchange, error) {
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Exchange{secret: secret, cookie: make([]byte, cookieSize), private: private}, nil
+}
+
+// Hello builds the hello datagram to send to the server (with the last cookie of the server),
+// the first hello is not authenticated, the client does not know the salt of the room yet
+func (e *Exchange) Hello() []byte {
+	public := e.private.PublicKey().Bytes()
+
+	datagram := append([]byte{KindHello}, e.cookie...)
+	datagram = append(datagram, public...)
+	if e.key == nil {
+		return append(datagram, make([]byte, macSize)...)
+	}
+	return append(datagram, e.key.mac("pong/hello/v3", e.cookie, public)...)
+}
+
+// WithCookie keeps the cookie datagram of the server to send the hello again,
+// it derives the key of the room from the salt of the server
+func (e *Exchange) WithCookie(datagram []byte) error {
+	if len(datagram) != 1+cookieSize+saltSize || datagram[0] != KindCookie {
+		return ErrUnauthenticated
+	}
+	if salt := datagram[1+cookieSize:]; e.key == nil || !bytes.Equal(salt, e.salt) {
+		key, err := e.secret.derive(salt)
+		if err != nil {
+			return err
+		}
+		e.key, e.salt = key, append([]byte(nil), salt...)
+	}
+	e.cookie = append(e.cookie[:0], datagram[1:1+cookieSize]...)
+	return nil
+}
+
+// Finish checks the key datagram of the server and it returns the session of the client
+func (e *Exchange) Finish(datagram []byte) (*Session, error) {
+	if len(datagram) != replySize || datagram[0] != KindKey || e.key == nil {
+		return nil, ErrUnauthenticated
+	}
+	clientPublic := e.private.PublicKey().Bytes()
+	serverPublic, mac := datagram[1:1+keySize], datagram[1+keySize:]
+	if !hmac.Equal(mac, e.key.mac("pong/key/v3", clientPublic, serverPublic)) {
+		return nil, ErrUnauthenticated
+	}
+
+	peer, err := ecdh.X25519().NewPublicKey(serverPublic)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	shared, err := e.private.ECDH(peer)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	return newSession(e.key, shared, clientPublic, serverPublic, false)
+}
+
+// Acceptor accepts the key exchanges of the clients of a server: a hello must carry a fresh cookie
+// issued to the address which sends it and a hello is accepted once (a replayed one is rejected)
+type Acceptor struct {
+	// key is the key of the room derived once from the secret and the {salt} of the server
+	key       key
+	salt      []byte
+	cookieKey key
+
+	mu sync.Mutex
+	// accepted are the client public keys of the hellos accepted with a fresh cookie
+	accepted map[string]time.Time
+	// hellos are the tokens of the addresses which send hellos
+	hellos map[string]*tokens
+}
+
+// tokens represents the hellos an address can still send
+type tokens struct {
+	available float64
+	at        time.Time
+}
+
+// NewAcceptor builds a new {Acceptor} type of the room secured by the {secret},
+// it derives the key of the room from a new salt
+func NewAcceptor(secret Secret) (*Acceptor, error) {
+	salt, cookieKey := make([]byte, saltSize), make(key, keySize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(cookieKey); err != nil {
+		return nil, err
+	}
+	key, err := secret.derive(salt)
+	if err != nil {
+		return nil, err
+	}
+	return &Acceptor{
+		key:       key,
+		salt:      salt,
+		cookieKey: cookieKey,
+		accepted:  make(map[string]time.Time),
+		hellos:    make(map[string]*tokens),
+	}, nil
+}
+
+// Accept checks the {hello} datagram of the client at the {addr} address and it returns the session of the server
+// and the key datagram to send back to the client, or only the cookie datagram to send back when the cookie is missing
+func (a *Acceptor) Accept(addr string, hello []byte) (*Session, []byte, error) {
+	if len(hello) != helloSize || hello[0] != KindHello {
+		return nil, nil, ErrUnauthenticated
+	}
+	if !a.allow(addr, time.Now()) {
+		return nil, nil, ErrRateLimited
+	}
+	cookie := hello[1 : 1+cookieSize]
+	clientPublic := hello[1+cookieSize : 1+cookieSize+keySize]
+	mac := hello[1+cookieSize+keySize:]
+
+	// the cookie costs nothing to the server, the key exchange is done only for an address which answered it
+	fresh, err := a.checkCookie(addr, cookie)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !fresh {
+		return nil, a.cookie(addr, time.Now()), nil
+	}
+	if !hmac.Equal(mac, a.key.mac("pong/hello/v3", cookie, clientPublic)) {
+		return nil, nil, ErrUnauthenticated
+	}
+
+	a.mu.Lock()
+	if _, replayed := a.accepted[string(clientPublic)]; replayed {
+		a.mu.Unlock()
+		return nil, nil, ErrReplayed
+	}
+	now := time.Now()
+	for public, at := range a.accepted {
+		if now.Sub(at) > cookieMaxAge {
+			delete(a.accepted, public) // its cookie is expired
+		}
+	}
+	a.accepted[string(clientPublic)] = now
+	a.mu.Unlock()
+
+	peer, err := ecdh.X25519().NewPublicKey(clientPublic)
+	if err != nil {
+		return nil, nil, ErrUnauthenticated
+	}
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	shared, err := private.ECDH(peer)
+	if err != nil {
+		return nil, nil, ErrUnauthenticated
+	}
+
+	serverPublic := private.PublicKey().Bytes()
+	session, err := newSession(a.key, shared, clientPublic, serverPublic, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reply := append([]byte{KindKey}, serverPublic...)
+	return session, append(reply, a.key.mac("pong/key/v3", clientPublic, serverPublic)...), nil
+}
+
+// allow returns true if the {addr} address does not exceed the rate limit of the hellos at {now}
+func (a *Acceptor) allow(addr string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for other, t := range a.hellos {
+		if now.Sub(t.at).Seconds()*hellosPerSecond >= hellosBurst {
+			delete(a.hellos, other) // its tokens are refilled
+		}
+	}
+	t, ok := a.hellos[addr]
+	if !ok {
+		t = &tokens{available: hellosBurst, at: now}
+		a.hellos[addr] = t
+	}
+	t.available = math.Min(hellosBurst, t.available+now.Sub(t.at).Seconds()*hellosPerSecond)
+	t.at = now
+
+	if t.available < 1 {
+		return false
+	}
+	t.available--
+	return true
+}
+
+// cookie builds the cookie datagram issued to the {addr} address at {now}, followed by the salt of the room
+func (a *Acceptor) cookie(addr string, now time.Time) []byte {
+	timestamp := binary.BigEndian.AppendUint64(nil, uint64(now.UnixNano()))
+	datagram := append([]byte{KindCookie}, timestamp...)
+	datagram = append(datagram, a.cookieKey.mac("pong/cookie/v1", []byte(addr), timestamp)...)
+	return append(datagram, a.salt...)
+}
+
+// checkCookie returns true if the {cookie} was issued to the {addr} address and is fresh,
+// false if the hello has no cookie (or an expired one) and an error if the cookie is forged
+func (a *Acceptor) checkCookie(addr string, cookie []byte) (bool, error) {
+	timestamp := cookie[:8]
+	if bytes.Equal(cookie, make([]byte, cookieSize)) {
+		return false, nil
+	}
+	issuedAt := time.Unix(0, int64(binary.BigEndian.Uint64(timestamp)))
+	if !hmac.Equal(cookie, a.cookie(addr, issuedAt)[1:1+cookieSize]) {
+		return false, fmt.Errorf("%w: cookie of another address", ErrUnauthenticated)
+	}
+	if age := time.Since(issuedAt); age > cookieMaxAge || age < 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// Session seals and opens the datagrams of a peer once the key exchange is done
+type Session struct {
+	aead       cipher.AEAD
+	sendPrefix []byte
+	recvPrefix []byte
+
+	mu       sync.Mutex
+	sent     uint64
+	received uint64
+	window   uint64
+}
+
+// newSession derives the key of the session from the {shared} secret of the key exchange and from the {key} of the room
+func newSession(key key, shared, clientPublic, serverPublic []byte, server bool) (*Session, error) {
+	sessionKey := make([]byte, keySize)
+	info := append(append([]byte("pong/session/v3"), clientPublic...), serverPublic...)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, key, info), sessionKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// each direction has its own nonces
+	clientToServer, serverToClient := []byte("c2s\x00"), []byte("s2c\x00")
+	session := &Session{aead: aead, sendPrefix: clientToServer, recvPrefix: serverToClient}
+	if server {
+		session.sendPrefix, session.recvPrefix = serverToClient, clientToServer
+	}
+	return session, nil
+}
+
+// Seal encrypts and authenticates the {plaintext} into a sealed datagram
+func (s *Session) Seal(plaintext []byte) []byte {
+	s.mu.Lock()
+	s.sent++
+	counter := s.sent
+	s.mu.Unlock()
+
+	header := binary.BigEndian.AppendUint64([]byte{KindSealed}, counter)
+	return s.aead.Seal(header, s.nonce(s.sendPrefix, counter), plaintext, header)
+}
+
+// Open authenticates and decrypts the sealed {datagram}
+func (s *Session) Open(datagram []byte) ([]byte, error) {
+	if len(datagram) < 1+counterSize || datagram[0] != KindSealed {
+		return nil, ErrUnauthenticated
+	}
+	header := datagram[:1+counterSize]
+	counter := binary.BigEndian.Uint64(header[1:])
+
+	plaintext, err := s.aead.Open(nil, s.nonce(s.recvPrefix, counter), datagram[len(header):], header)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.accept(counter) {
+		return nil, ErrReplayed
+	}
+	return plaintext, nil
+}
+
+// accept checks the {counter} against the sliding window of the last received counters
+func (s *Session) accept(counter uint64) bool {
+	switch {
+	case counter == 0:
+		return false
+	case counter > s.received:
+		if shift := counter - s.received; shift < replayWindow {
+			s.window = s.window<<shift | 1
+		} else {
+			s.window = 1
+		}
+		s.received = counter
+		return true
+	case s.received-counter >= replayWindow:
+		return false
+	default:
+		bit := uint64(1) << (s.received - counter)
+		if s.window&bit != 0 {
+			return false
+		}
+		s.window |= bit
+		return true
+	}
+}
+
+func (s *Session) nonce(prefix []byte, counter uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte(nil), prefix...), counter)
+}
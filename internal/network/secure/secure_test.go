@@ -0,0 +1,202 @@
+package secure
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// handshake runs the key exchange between a client and a server
+func handshake(t *testing.T, clientSecret, serverSecret Secret) (*Session, *Session, error) {
+	t.Helper()
+
+	exchange, err := NewExchange(clientSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acceptor := newAcceptor(t, serverSecret)
+	fetchCookie(t, exchange, acceptor, "127.0.0.1:4000")
+	server, reply, err := acceptor.Accept("127.0.0.1:4000", exchange.Hello())
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := exchange.Finish(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, server, nil
+}
+
+// newAcceptor builds the acceptor of the room secured by the {secret}
+func newAcceptor(t *testing.T, secret Secret) *Acceptor {
+	t.Helper()
+
+	acceptor, err := NewAcceptor(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acceptor
+}
+
+// fetchCookie sends the hello without cookie of the {exchange} from the {addr} address and it keeps the cookie of the server
+func fetchCookie(t *testing.T, exchange *Exchange, acceptor *Acceptor, addr string) {
+	t.Helper()
+
+	session, reply, err := acceptor.Accept(addr, exchange.Hello())
+	if err != nil || session != nil || Kind(reply) != KindCookie {
+		t.Fatalf("got %v %v, want a cookie", session, err)
+	}
+	if err := exchange.WithCookie(reply); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSealAndOpen(t *testing.T) {
+	client, server, err := handshake(t, NewSecret("room"), NewSecret("room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, peers := range [][2]*Session{{client, server}, {server, client}} {
+		datagram := peers[0].Seal([]byte(`{"data":{"cmd":"Ping"}}`))
+		if Kind(datagram) != KindSealed {
+			t.Fatalf("got kind %q, want a sealed datagram", Kind(datagram))
+		}
+		plaintext, err := peers[1].Open(datagram)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(plaintext) != `{"data":{"cmd":"Ping"}}` {
+			t.Errorf("got %s", plaintext)
+		}
+	}
+}
+
+func TestAcceptWithWrongSecret(t *testing.T) {
+	if _, _, err := handshake(t, NewSecret("room"), NewSecret("other")); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("got %v, want %v", err, ErrUnauthenticated)
+	}
+}
+
+func TestOpenTamperedDatagram(t *testing.T) {
+	client, server, err := handshake(t, NewSecret("room"), NewSecret("room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	datagram := client.Seal([]byte("paddle"))
+	datagram[len(datagram)-1] ^= 0xff
+	if _, err := server.Open(datagram); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("got %v, want %v", err, ErrUnauthenticated)
+	}
+
+	// a datagram sealed by the server can not be reflected to the server
+	if _, err := server.Open(server.Seal([]byte("paddle"))); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("got %v, want %v", err, ErrUnauthenticated)
+	}
+}
+
+func TestOpenReplayedAndReorderedDatagrams(t *testing.T) {
+	client, server, err := handshake(t, NewSecret("room"), NewSecret("room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second, third := client.Seal([]byte("1")), client.Seal([]byte("2")), client.Seal([]byte("3"))
+	for _, datagram := range [][]byte{third, first, second} {
+		if _, err := server.Open(datagram); err != nil {
+			t.Fatalf("reordered datagram: %v", err)
+		}
+	}
+	if _, err := server.Open(second); !errors.Is(err, ErrReplayed) {
+		t.Fatalf("got %v, want %v", err, ErrReplayed)
+	}
+
+	for i := 0; i < replayWindow; i++ {
+		if _, err := server.Open(client.Seal([]byte("n"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := server.Open(third); !errors.Is(err, ErrReplayed) {
+		t.Fatalf("too old datagram: got %v, want %v", err, ErrReplayed)
+	}
+}
+
+func TestAcceptReplayedHello(t *testing.T) {
+	acceptor := newAcceptor(t, NewSecret("room"))
+	exchange, err := NewExchange(NewSecret("room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetchCookie(t, exchange, acceptor, "127.0.0.1:4000")
+	hello := exchange.Hello()
+	if session, _, err := acceptor.Accept("127.0.0.1:4000", hello); err != nil || session == nil {
+		t.Fatalf("got %v %v, want a session", session, err)
+	}
+
+	// the captured hello replayed from a spoofed address or from the address of the peer
+	if session, _, err := acceptor.Accept("127.0.0.1:5000", hello); !errors.Is(err, ErrUnauthenticated) || session != nil {
+		t.Errorf("spoofed address: got %v %v, want %v", session, err, ErrUnauthenticated)
+	}
+	if session, _, err := acceptor.Accept("127.0.0.1:4000", hello); !errors.Is(err, ErrReplayed) || session != nil {
+		t.Errorf("same address: got %v %v, want %v", session, err, ErrReplayed)
+	}
+}
+
+func TestAcceptExpiredCookie(t *testing.T) {
+	acceptor := newAcceptor(t, NewSecret("room"))
+	exchange, err := NewExchange(NewSecret("room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := acceptor.cookie("127.0.0.1:4000", time.Now().Add(-2*cookieMaxAge))
+	if err := exchange.WithCookie(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server answers with a new cookie
+	if session, reply, err := acceptor.Accept("127.0.0.1:4000", exchange.Hello()); err != nil || session != nil || Kind(reply) != KindCookie {
+		t.Fatalf("got %v %v, want a new cookie", session, err)
+	}
+}
+
+func TestNewAcceptorSalt(t *testing.T) {
+	first, second := newAcceptor(t, NewSecret("room")), newAcceptor(t, NewSecret("room"))
+	if bytes.Equal(first.salt, second.salt) || bytes.Equal(first.key, second.key) {
+		t.Error("expected a key of the room derived from a salt of each server")
+	}
+
+	// the client derives the key of the room from the salt of the cookie
+	exchange, err := NewExchange(NewSecret("room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetchCookie(t, exchange, first, "127.0.0.1:4000")
+	if !bytes.Equal(exchange.key, first.key) {
+		t.Error("expected the key of the room on the client")
+	}
+}
+
+func TestAcceptRateLimit(t *testing.T) {
+	acceptor := newAcceptor(t, NewSecret("room"))
+	exchange, err := NewExchange(NewSecret("room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < hellosBurst; i++ {
+		if _, _, err := acceptor.Accept("127.0.0.1:4000", exchange.Hello()); err != nil {
+			t.Fatalf("hello %d should be allowed by the burst: %v", i, err)
+		}
+	}
+	if _, _, err := acceptor.Accept("127.0.0.1:4000", exchange.Hello()); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got %v, want %v", err, ErrRateLimited)
+	}
+	if _, _, err := acceptor.Accept("127.0.0.1:5000", exchange.Hello()); err != nil {
+		t.Errorf("another address should not be limited: %v", err)
+	}
+	if !acceptor.allow("127.0.0.1:4000", time.Now().Add(time.Second)) {
+		t.Error("hello should be allowed once the tokens are refilled")
+	}
+}
@@ -1,6 +1,12 @@
 package network
 
-import "time"
+import (
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/logging"
+)
+
+var tickerLogger = logging.For(logging.Ticker)
 
 type Ticker struct {
 	Ticker *time.Ticker
@@ -11,6 +17,7 @@ func (t *Ticker) Run(send func(Message), messages chan<- Message) {
 	for {
 		select {
 		case <-t.Done:
+			tickerLogger.Debug("stop the ticker")
 			t.Ticker.Stop()
 			return
 		case <-t.Ticker.C:
@@ -20,6 +27,7 @@ func (t *Ticker) Run(send func(Message), messages chan<- Message) {
 }
 
 func (t *Ticker) Ping(send func(Message), messages chan<- Message) {
+	tickerLogger.Debug("ping all subscribers")
 	send(NewSimpleMessage(Ping.String()))
 	messages <- NewSimpleMessage(PingAll.String())
 }
@@ -1,15 +1,22 @@
 package udp
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/metrics"
 	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/internal/network/secure"
 	"github.com/joakim-ribier/pong/pkg"
 )
 
+var clientLogger = logging.For(logging.UDPClient)
+
 // UDPClient represents a client connection
 type UDPClient struct {
 	serverAddr *net.UDPAddr
@@ -17,18 +24,66 @@ type UDPClient struct {
 	conn             *net.UDPConn
 	connectionClosed bool
 	ticker           network.Ticker
+
+	// secret authenticates the client in a secured room (nil if the room is open)
+	secret   secure.Secret
+	exchange *secure.Exchange
+	session  *secure.Session
+	pending  []byte
+	mu       sync.Mutex
+
+	// token resumes a session issued by the server (e.g. the seat of a former host which handed the match over)
+	token string
 }
 
-// NewClient builds a new {UDPClient} type
-func NewClient(serverAddr string) *UDPClient {
+// NewClient builds a new {UDPClient} type which listens on a random port
+// to talk to the {serverAddr} server (the local machine if the host is empty)
+func NewClient(serverAddr string) (*UDPClient, error) {
+	addr, err := pkg.ResolveUDPAddr(serverAddr)
+	if err != nil {
+		return nil, err
+	}
+	if addr.Port == 0 {
+		return nil, fmt.Errorf("invalid address '%s': the server port is missing", serverAddr)
+	}
+	if addr.IP == nil {
+		addr.IP = net.IPv4(127, 0, 0, 1)
+	}
+
+	// listen on all the interfaces (dual-stack) to talk to an IPv4 or IPv6 server
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, fmt.Errorf("fail to listen on udp: %w", err)
+	}
+
 	return &UDPClient{
 		connectionClosed: false,
-		serverAddr:       pkg.ToUDPAddrUnsafe(serverAddr),
+		serverAddr:       addr,
+		conn:             conn,
 		ticker: network.Ticker{
 			Ticker: time.NewTicker(5 * time.Second),
 			Done:   make(chan bool),
 		},
+	}, nil
+}
+
+// WithSecret joins a room secured by the {password} shared secret
+func (c *UDPClient) WithSecret(password string) *UDPClient {
+	if password != "" {
+		c.secret = secure.NewSecret(password)
 	}
+	return c
+}
+
+// WithSession subscribes the client with the {token} of a session issued by the server
+func (c *UDPClient) WithSession(token string) *UDPClient {
+	c.token = token
+	return c
+}
+
+// LocalAddr returns the local network address of the client
+func (c *UDPClient) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
 }
 
 // ListenAndServe listens messages from UDP network on a specific port
@@ -36,24 +91,20 @@ func NewClient(serverAddr string) *UDPClient {
 func (c *UDPClient) ListenAndServe(messages chan<- network.Message) {
 	go c.ticker.Run(c.Send, messages)
 
-	addr := &net.UDPAddr{
-		IP:   net.IPv4(0, 0, 0, 0),
-		Port: 0,
-		Zone: "",
-	}
-
-	conn, err := net.ListenUDP("udp", addr)
-	if err != nil {
-		log.Printf("fail to listen on udp://%s: %v", addr.String(), err)
-	}
-	c.conn = conn
-	c.connectionClosed = false
-
 	defer c.close()
-	log.Printf("listening on udp://%s network...", conn.LocalAddr())
+	clientLogger.Info("listening on network...", "addr", c.conn.LocalAddr().String(), "server", c.serverAddr.String())
 
 	// subscribe the client to the server
-	c.Send(network.NewSimpleMessage(network.Subscribe.String()))
+	c.Send(network.NewMessage(network.Subscribe.String(), network.NewHello(c.token)))
+	if c.secret != nil {
+		time.AfterFunc(3*time.Second, func() {
+			if c.secured() == nil {
+				// the server ignores the key exchange of a client with a wrong secret
+				messages <- network.NewMessage(network.Subscribe.String(),
+					network.NewRefusal("no answer to the key exchange, check the secret")).WithAddr(c.serverAddr.String())
+			}
+		})
+	}
 	time.Sleep(500 * time.Millisecond)
 
 	c.ticker.Ping(c.Send, messages)
@@ -64,19 +115,33 @@ func (c *UDPClient) ListenAndServe(messages chan<- network.Message) {
 // and it notifies the application with the {messages} chan
 func (c *UDPClient) read(messages chan<- network.Message) {
 	for {
-		buf := make([]byte, 1024)
+		buf := make([]byte, 65535)
 		size, networkAddr, err := c.conn.ReadFrom(buf)
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
 			continue
 		}
 
-		message, err := jsonsutil.Unmarshal[network.Message](buf[:size])
+		datagram, err := c.open(buf[:size])
 		if err != nil {
-			log.Printf("fail to read message from udp://%s: %v", networkAddr.String(), err)
+			clientLogger.Warn("reject datagram", "addr", networkAddr.String(), "err", err)
 			continue
 		}
+		if datagram == nil {
+			continue // key exchange
+		}
 
-		log.Printf("read message from udp://%s: %v", networkAddr.String(), message)
+		message, err := jsonsutil.Unmarshal[network.Message](datagram)
+		if err != nil {
+			metrics.DecodeErrors.Inc()
+			clientLogger.Warn("fail to read message", "addr", networkAddr.String(), "err", err)
+			continue
+		}
+
+		clientLogger.Debug("read message", "addr", networkAddr.String(), "cmd", message.Data.Cmd, "value", message.Data.Value)
+		metrics.MessagesIn.With(message.AsCMD().String()).Inc()
 		messages <- message.WithAddr(networkAddr.String())
 
 		if message.AsCMD() == network.Shutdown {
@@ -88,16 +153,15 @@ func (c *UDPClient) read(messages chan<- network.Message) {
 // close closes the UDP connection
 func (c *UDPClient) close() {
 	if c.conn != nil && !c.connectionClosed {
-		log.Printf("close the udp://%s connection", c.conn.LocalAddr().String())
+		clientLogger.Info("close the connection", "addr", c.conn.LocalAddr().String())
 
 		c.connectionClosed = true
 		c.ticker.Done <- true
-		c.Send(network.NewSimpleMessage(network.Shutdown.String())) // notify the server before shutdown
+		c.Send(network.NewSimpleMessage(network.Shutdown.String())) // notify the server before shutdown (written at once)
 
-		time.Sleep(1 * time.Second)
 		err := c.conn.Close()
 		if err != nil {
-			log.Printf("fail to close udp://%s connection: %v", c.serverAddr.String(), err)
+			clientLogger.Error("fail to close the connection", "addr", c.serverAddr.String(), "err", err)
 		}
 	}
 }
@@ -110,12 +174,108 @@ func (c *UDPClient) Shutdown() {
 // Send sends the {network.Message} to the server
 func (c *UDPClient) Send(msg network.Message) {
 	if c.conn != nil {
-		log.Printf("send a message to udp://%s: %v", c.serverAddr.String(), msg)
+		clientLogger.Debug("send message", "addr", c.serverAddr.String(), "cmd", msg.Data.Cmd, "value", msg.Data.Value)
 
 		bytes, _ := jsonsutil.Marshal(msg)
-		_, err := c.conn.WriteTo(bytes, c.serverAddr)
+		bytes, err := c.seal(msg, bytes)
+		if err != nil {
+			clientLogger.Warn("fail to send message", "addr", c.serverAddr.String(), "cmd", msg.Data.Cmd, "err", err)
+			return
+		}
+		if bytes == nil {
+			return
+		}
+
+		_, err = c.conn.WriteTo(bytes, c.serverAddr)
+		if err != nil {
+			clientLogger.Warn("fail to send message", "addr", c.serverAddr.String(), "err", err)
+		} else {
+			metrics.MessagesOut.With(msg.AsCMD().String()).Inc()
+		}
+	}
+}
+
+// seal seals the {bytes} of the {msg} message in a secured room,
+// a [subscribe] message starts a new key exchange and it is sent once the exchange is done
+func (c *UDPClient) seal(msg network.Message, bytes []byte) ([]byte, error) {
+	if c.secret == nil {
+		return bytes, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if msg.AsCMD() == network.Subscribe {
+		exchange, err := secure.NewExchange(c.secret)
 		if err != nil {
-			log.Printf("fail to send message to udp://%s: %v", c.serverAddr.String(), err)
+			return nil, err
+		}
+		c.exchange, c.pending = exchange, bytes
+		if _, err := c.conn.WriteTo(exchange.Hello(), c.serverAddr); err != nil {
+			return nil, err
 		}
+		return nil, nil
+	}
+	if c.session == nil {
+		return nil, errors.New("no secured session")
+	}
+	return c.session.Seal(bytes), nil
+}
+
+// open authenticates the {datagram} of the server in a secured room and it returns its content,
+// it finishes the key exchange and it returns nil for the handshake datagrams
+func (c *UDPClient) open(datagram []byte) ([]byte, error) {
+	if c.secret == nil {
+		return datagram, nil
 	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch secure.Kind(datagram) {
+	case secure.KindCookie:
+		if c.exchange == nil {
+			return nil, secure.ErrUnauthenticated
+		}
+		// send the hello again with the cookie of the server
+		if err := c.exchange.WithCookie(datagram); err != nil {
+			return nil, err
+		}
+		if _, err := c.conn.WriteTo(c.exchange.Hello(), c.serverAddr); err != nil {
+			clientLogger.Warn("fail to send the key exchange", "addr", c.serverAddr.String(), "err", err)
+		}
+		return nil, nil
+	case secure.KindKey:
+		if c.exchange == nil {
+			return nil, secure.ErrUnauthenticated
+		}
+		session, err := c.exchange.Finish(datagram)
+		if err != nil {
+			return nil, err
+		}
+		c.session, c.exchange = session, nil
+
+		// send the pending [subscribe] message
+		if _, err := c.conn.WriteTo(c.session.Seal(c.pending), c.serverAddr); err != nil {
+			clientLogger.Warn("fail to send message", "addr", c.serverAddr.String(), "err", err)
+		} else {
+			metrics.MessagesOut.With(network.Subscribe.String()).Inc()
+		}
+		c.pending = nil
+		return nil, nil
+	case secure.KindSealed:
+		if c.session == nil {
+			return nil, secure.ErrUnauthenticated
+		}
+		return c.session.Open(datagram)
+	}
+
+	return nil, secure.ErrUnauthenticated
+}
+
+// secured returns the secured session of the client (nil before the key exchange)
+func (c *UDPClient) secured() *secure.Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
 }
@@ -1,15 +1,28 @@
 package udp
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/pong/internal/logging"
+	"github.com/joakim-ribier/pong/internal/metrics"
 	"github.com/joakim-ribier/pong/internal/network"
+	"github.com/joakim-ribier/pong/internal/network/secure"
 	"github.com/joakim-ribier/pong/pkg"
 )
 
+var serverLogger = logging.For(logging.UDPServer)
+
+// flushTimeout is the time given to the subscribers to write their last messages on shutdown
+const flushTimeout = 500 * time.Millisecond
+
+// hellosBuffer is the number of key exchanges waiting to be answered, the next ones are dropped
+const hellosBuffer = 16
+
 // UDPServer represents a server connection
 type UDPServer struct {
 	networkAddr *net.UDPAddr
@@ -17,20 +30,67 @@ type UDPServer struct {
 
 	hub    *network.Hub
 	ticker network.Ticker
+	// listeners are the goroutines which write to the subscribers
+	listeners sync.WaitGroup
 
 	conn *net.UDPConn
+
+	// acceptor authenticates the subscribers of a secured room (nil if the room is open)
+	acceptor *secure.Acceptor
+	sessions map[string]*secure.Session
+	mu       sync.Mutex
+	// hellos are the key exchanges handled out of the read loop
+	hellos chan hello
 }
 
-// NewServer builds a new {UDPServer} type
-func NewServer(serverAddr string) *UDPServer {
+// hello represents the hello datagram of a key exchange sent by the {addr} address
+type hello struct {
+	addr     net.Addr
+	datagram []byte
+}
+
+// NewServer builds a new {UDPServer} type which listens on the {serverAddr} address
+// (on all the interfaces in dual-stack IPv4/IPv6 if the host is empty)
+func NewServer(serverAddr string) (*UDPServer, error) {
+	addr, err := pkg.ResolveUDPAddr(serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("fail to listen on udp://%s: %w", addr.String(), err)
+	}
+
 	return &UDPServer{
-		networkAddr: pkg.ToUDPAddrUnsafe(serverAddr),
+		networkAddr: conn.LocalAddr().(*net.UDPAddr),
+		conn:        conn,
 		hub:         network.NewHub(),
+		sessions:    make(map[string]*secure.Session),
+		hellos:      make(chan hello, hellosBuffer),
 		ticker: network.Ticker{
 			Ticker: time.NewTicker(5 * time.Second),
 			Done:   make(chan bool),
 		},
+	}, nil
+}
+
+// WithSecret secures the room with the {password} shared secret,
+// the datagrams which are not authenticated with it are rejected
+func (s *UDPServer) WithSecret(password string) (*UDPServer, error) {
+	if password != "" {
+		acceptor, err := secure.NewAcceptor(secure.NewSecret(password))
+		if err != nil {
+			return nil, fmt.Errorf("fail to secure the room: %w", err)
+		}
+		s.acceptor = acceptor
 	}
+	return s, nil
+}
+
+// LocalAddr returns the local network address of the server
+func (s *UDPServer) LocalAddr() net.Addr {
+	return s.networkAddr
 }
 
 // ListenAndServe listens messages from UDP network on a specific port
@@ -38,72 +98,138 @@ func NewServer(serverAddr string) *UDPServer {
 func (s *UDPServer) ListenAndServe(messages chan<- network.Message) {
 	go s.hub.Run()
 	go s.ticker.Run(s.Send, messages)
-
-	conn, err := net.ListenUDP("udp", s.networkAddr)
-	if err != nil {
-		log.Printf("fail to listen on udp://%s: %v", s.networkAddr.String(), err)
+	if s.acceptor != nil {
+		go s.handshake()
 	}
-	s.conn = conn
-	s.closed = false
 
-	log.Printf("listening on udp://%s network...", conn.LocalAddr())
+	serverLogger.Info("listening on network...", "addr", s.networkAddr.String())
 	s.read(messages)
 }
 
-// Shutdown closes the UDP connection
+// Shutdown closes the UDP connection once
 // and it sends a message to all subscribers
 func (s *UDPServer) Shutdown() {
-	if !s.closed {
-		log.Printf("close the udp://%s connection", s.networkAddr)
-		s.hub.Shutdown()
-		s.closed = true
-
-		time.Sleep(1 * time.Second)
-		if err := s.conn.Close(); err != nil {
-			log.Printf("fail to close udp://%s connection: %v", s.networkAddr, err)
-			s.closed = false
-		}
+	s.mu.Lock()
+	closed := s.closed
+	s.closed = true
+	s.mu.Unlock()
+	if closed {
+		return
+	}
+
+	serverLogger.Info("close the connection", "addr", s.networkAddr.String())
+	close(s.ticker.Done)
+	s.hub.Shutdown()
+	// the subscribers write their last messages (e.g. [shutdown]) before the connection is closed
+	flushed := make(chan struct{})
+	go func() {
+		s.listeners.Wait()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+	case <-time.After(flushTimeout):
+		serverLogger.Warn("close the connection before the last messages", "addr", s.networkAddr.String())
 	}
+	s.hub.Stop()
+
+	if err := s.conn.Close(); err != nil {
+		serverLogger.Error("fail to close the connection", "addr", s.networkAddr.String(), "err", err)
+	}
+}
+
+// isClosed returns true once the server is shut down
+func (s *UDPServer) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
 }
 
 // Send sends the {network.Message} to the specific subscriber
-// or it broadcasts the message to all subscribers
+// or it broadcasts the message to all subscribers,
+// a [shutdown] message to a specific subscriber unregisters it
 func (s *UDPServer) Send(msg network.Message) {
-	if subscriber, ok := s.hub.Subscribers[msg.NetworkAddr]; ok {
-		subscriber.Publish <- msg
+	if s.isClosed() {
+		return
+	}
+	if subscriber, ok := s.hub.Get(msg.NetworkAddr); ok {
+		if msg.AsCMD() == network.Shutdown {
+			network.Post(s.hub, s.hub.Unregister, subscriber.NetworkAddr)
+			return
+		}
+		network.Post(s.hub, subscriber.Publish, msg)
+	} else if msg.AsCMD() == network.Shutdown && msg.NetworkAddr != "" {
+		return // the subscriber is already unregistered
 	} else {
-		s.hub.Broadcast <- msg
+		network.Post(s.hub, s.hub.Broadcast, msg)
 	}
 }
 
 // read reads messages from network connection
 // and it notifies the application with the {messages} chan
 func (s *UDPServer) read(messages chan<- network.Message) {
+	defer close(s.hellos)
 	for {
-		buf := make([]byte, 1024)
+		buf := make([]byte, 65535)
 		size, remoteAddr, err := s.conn.ReadFrom(buf)
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		// the key exchanges do not hold the datagrams of the game
+		if s.acceptor != nil && secure.Kind(buf[:size]) == secure.KindHello {
+			select {
+			case s.hellos <- hello{addr: remoteAddr, datagram: buf[:size]}:
+			default:
+				metrics.RejectedDatagrams.Inc()
+				serverLogger.Warn("reject datagram", "addr", remoteAddr.String(), "err", "too many key exchanges")
+			}
 			continue
 		}
 
-		message, err := jsonsutil.Unmarshal[network.Message](buf[:size])
+		datagram, err := s.open(remoteAddr, buf[:size])
 		if err != nil {
-			log.Printf("fail to read message from udp://%s: %v", remoteAddr.String(), err)
+			metrics.RejectedDatagrams.Inc()
+			serverLogger.Warn("reject datagram", "addr", remoteAddr.String(), "err", err)
 			continue
 		}
-		log.Printf("read message from udp://%s: %v", remoteAddr.String(), message)
+
+		message, err := jsonsutil.Unmarshal[network.Message](datagram)
+		if err != nil {
+			metrics.DecodeErrors.Inc()
+			serverLogger.Warn("fail to read message", "addr", remoteAddr.String(), "err", err)
+			continue
+		}
+		serverLogger.Debug("read message", "addr", remoteAddr.String(), "cmd", message.Data.Cmd, "value", message.Data.Value)
+		metrics.MessagesIn.With(message.AsCMD().String()).Inc()
 
 		switch message.AsCMD() {
 		case network.Subscribe:
+			// a subscriber can subscribe again to resume its session
+			if _, ok := s.hub.Get(remoteAddr.String()); ok {
+				break
+			}
 			subscriber := &network.Subscriber{
 				NetworkAddr: remoteAddr.String(),
+				Addr:        remoteAddr,
 				Publish:     make(chan network.Message, 16),
 				Shutdown:    make(chan int, 1),
 			}
-			s.hub.Register <- subscriber
+			// no subscriber is registered while the server shuts down
+			s.mu.Lock()
+			if s.closed {
+				s.mu.Unlock()
+				continue
+			}
+			s.listeners.Add(1)
+			network.Post(s.hub, s.hub.Register, subscriber)
+			s.mu.Unlock()
 			go s.listen(subscriber)
 		case network.Shutdown:
-			s.hub.Unregister <- remoteAddr.String()
+			network.Post(s.hub, s.hub.Unregister, remoteAddr.String())
 		}
 
 		messages <- message.WithAddr(remoteAddr.String())
@@ -117,18 +243,98 @@ func (s *UDPServer) read(messages chan<- network.Message) {
 
 // listen listens messages on a specific subscriber
 func (s *UDPServer) listen(subscriber *network.Subscriber) {
+	defer s.listeners.Done()
 	for {
 		select {
+		case <-s.hub.Done:
+			return // the subscriber was dropped by the hub
 		case <-subscriber.Shutdown:
-			subscriber.Publish <- network.NewSimpleMessage(network.Shutdown.String())
+			// flush the last messages (e.g. [migrate]) before the [shutdown]
+			for len(subscriber.Publish) > 0 {
+				s.write(subscriber, <-subscriber.Publish)
+			}
+			s.write(subscriber, network.NewSimpleMessage(network.Shutdown.String()))
+			s.mu.Lock()
+			delete(s.sessions, subscriber.NetworkAddr)
+			s.mu.Unlock()
+			return
 		case msg := <-subscriber.Publish:
-			log.Printf("send a message to udp://%s: %v", subscriber.NetworkAddr, msg)
+			s.write(subscriber, msg)
+		}
+	}
+}
 
-			bytes, _ := jsonsutil.Marshal(msg)
-			_, err := s.conn.WriteTo(bytes, pkg.ToUDPAddrUnsafe(subscriber.NetworkAddr))
-			if err != nil {
-				log.Printf("fail to send message to udp://%s: %v", s.networkAddr, err)
-			}
+// write writes the {msg} to the {subscriber} on the UDP connection
+func (s *UDPServer) write(subscriber *network.Subscriber, msg network.Message) {
+	serverLogger.Debug("send message", "addr", subscriber.NetworkAddr, "cmd", msg.Data.Cmd, "value", msg.Data.Value)
+
+	bytes, _ := jsonsutil.Marshal(msg)
+	if s.acceptor != nil {
+		session := s.session(subscriber.NetworkAddr)
+		if session == nil {
+			serverLogger.Warn("fail to send message", "addr", subscriber.NetworkAddr, "err", "no secured session")
+			return
+		}
+		bytes = session.Seal(bytes)
+	}
+	_, err := s.conn.WriteTo(bytes, subscriber.Addr)
+	if err != nil {
+		serverLogger.Warn("fail to send message", "addr", subscriber.NetworkAddr, "err", err)
+	} else {
+		metrics.MessagesOut.With(msg.AsCMD().String()).Inc()
+	}
+}
+
+// open authenticates the sealed {datagram} of the {remoteAddr} in a secured room and it returns its content
+func (s *UDPServer) open(remoteAddr net.Addr, datagram []byte) ([]byte, error) {
+	if s.acceptor == nil {
+		return datagram, nil
+	}
+
+	if secure.Kind(datagram) == secure.KindSealed {
+		session := s.session(remoteAddr.String())
+		if session == nil {
+			return nil, secure.ErrUnauthenticated
 		}
+		return session.Open(datagram)
 	}
+
+	return nil, secure.ErrUnauthenticated
+}
+
+// handshake answers to the key exchanges of the subscribers until the connection is closed
+func (s *UDPServer) handshake() {
+	for hello := range s.hellos {
+		if err := s.accept(hello.addr, hello.datagram); err != nil {
+			metrics.RejectedDatagrams.Inc()
+			serverLogger.Warn("reject datagram", "addr", hello.addr.String(), "err", err)
+		}
+	}
+}
+
+// accept answers to the {datagram} hello of the {remoteAddr} with a cookie or with the key of its session
+func (s *UDPServer) accept(remoteAddr net.Addr, datagram []byte) error {
+	// a replayed hello (from a spoofed address or not) never replaces the session of a subscriber
+	session, reply, err := s.acceptor.Accept(remoteAddr.String(), datagram)
+	if err != nil {
+		return err
+	}
+	if session != nil {
+		s.mu.Lock()
+		s.sessions[remoteAddr.String()] = session
+		s.mu.Unlock()
+	}
+
+	serverLogger.Debug("key exchange", "addr", remoteAddr.String(), "cookie", session == nil)
+	if _, err := s.conn.WriteTo(reply, remoteAddr); err != nil {
+		serverLogger.Warn("fail to send the key exchange", "addr", remoteAddr.String(), "err", err)
+	}
+	return nil
+}
+
+// session returns the secured session of the {networkAddr} subscriber
+func (s *UDPServer) session(networkAddr string) *secure.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[networkAddr]
 }
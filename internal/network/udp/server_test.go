@@ -0,0 +1,73 @@
+package udp
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/pong/internal/network/secure"
+)
+
+// receive reads the next datagram of the {conn} connection
+func receive(t *testing.T, conn *net.UDPConn) []byte {
+	t.Helper()
+
+	buf := make([]byte, 1024)
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	size, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf[:size]
+}
+
+func TestReplayedHelloKeepsTheSession(t *testing.T) {
+	server, err := NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.conn.Close()
+	if _, err := server.WithSecret("room"); err != nil {
+		t.Fatal(err)
+	}
+
+	peer, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+
+	exchange, err := secure.NewExchange(secure.NewSecret("room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.accept(peer.LocalAddr(), exchange.Hello()); err != nil {
+		t.Fatal(err)
+	}
+	if err := exchange.WithCookie(receive(t, peer)); err != nil {
+		t.Fatal(err)
+	}
+	hello := exchange.Hello()
+	if err := server.accept(peer.LocalAddr(), hello); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exchange.Finish(receive(t, peer)); err != nil {
+		t.Fatal(err)
+	}
+	session := server.session(peer.LocalAddr().String())
+
+	// the captured hello replayed from a spoofed address and from the address of the peer
+	spoofed := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: peer.LocalAddr().(*net.UDPAddr).Port + 1}
+	if err := server.accept(spoofed, hello); !errors.Is(err, secure.ErrUnauthenticated) || server.session(spoofed.String()) != nil {
+		t.Errorf("spoofed address: got %v, want %v", err, secure.ErrUnauthenticated)
+	}
+	if err := server.accept(peer.LocalAddr(), hello); !errors.Is(err, secure.ErrReplayed) {
+		t.Errorf("same address: got %v, want %v", err, secure.ErrReplayed)
+	}
+	if server.session(peer.LocalAddr().String()) != session {
+		t.Error("the replayed hello replaced the session of the peer")
+	}
+}
@@ -0,0 +1,41 @@
+package pkg
+
+// Bot moves the paddle of a player played by the computer,
+// it follows the ball coming to its side and it goes back to the middle of the table
+type Bot struct {
+	Side PlayerSide
+	// Reach is the part of the table [0..1] from its side where the bot sees the ball
+	Reach float32
+}
+
+// NewBot builds a new {Bot} type which plays on the {side}
+func NewBot(side PlayerSide) *Bot {
+	return &Bot{Side: side, Reach: 0.6}
+}
+
+// Move returns the direction of the {paddle} (-1 up, 1 down or 0) to return the {ball} on the {screen}
+func (b Bot) Move(paddle Paddle, ball Ball, screen Screen) int {
+	target := float32(screen.GameZoneYCenter())
+	if b.sees(ball, screen) {
+		target = ball.Y + float32(ball.Height)/2
+	}
+
+	center := paddle.Y + float32(paddle.Height)/2
+	switch {
+	case target < center-paddle.Speed:
+		return -1
+	case target > center+paddle.Speed:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sees returns true if the {ball} comes to the side of the bot and if it is in its reach
+func (b Bot) sees(ball Ball, screen Screen) bool {
+	reach := b.Reach * float32(screen.GameZoneWidth())
+	if b.Side == PlayerLeft {
+		return ball.XSpeed < 0 && ball.X <= screen.XLeft+reach
+	}
+	return ball.XSpeed > 0 && ball.X >= screen.XRight-reach
+}
@@ -0,0 +1,32 @@
+package pkg
+
+import "testing"
+
+func TestBotMove(t *testing.T) {
+	screen := Screen{XLeft: 0, XRight: 1000, YBottom: 0, YTop: 600}
+	paddle := Paddle{Position: Position{Y: 250}, Speed: 10, Height: 100}
+	bot := NewBot(PlayerRight)
+
+	tests := []struct {
+		name     string
+		ball     Ball
+		expected int
+	}{
+		{"follows the ball up", Ball{Position: Position{X: 800, Y: 50}, XSpeed: 5, Height: 16}, -1},
+		{"follows the ball down", Ball{Position: Position{X: 800, Y: 500}, XSpeed: 5, Height: 16}, 1},
+		{"waits in front of the ball", Ball{Position: Position{X: 800, Y: 295}, XSpeed: 5, Height: 16}, 0},
+		{"ignores the ball out of reach", Ball{Position: Position{X: 100, Y: 50}, XSpeed: 5, Height: 16}, 0},
+		{"ignores the ball going away", Ball{Position: Position{X: 800, Y: 50}, XSpeed: -5, Height: 16}, 0},
+	}
+	for _, test := range tests {
+		if move := bot.Move(paddle, test.ball, screen); move != test.expected {
+			t.Errorf("%s: got %d, expected %d", test.name, move, test.expected)
+		}
+	}
+
+	// the bot goes back to the middle of the table
+	paddle.Y = 0
+	if move := NewBot(PlayerLeft).Move(paddle, Ball{Position: Position{X: 800}, XSpeed: 5}, screen); move != 1 {
+		t.Errorf("got %d, expected to go back to the middle", move)
+	}
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import "time"
+
+// Clock is the simulation clock of a game, it is advanced by one tick on each update of the game
+// (not by the wall clock) so the timers do not depend on the actual ticks per second
+type Clock struct {
+	ticks int64
+	tps   int
+}
+
+// NewClock builds a new {Clock} type which advances of 1/{tps} second on each tick
+func NewClock(tps int) *Clock {
+	return &Clock{tps: tps}
+}
+
+// Tick advances the clock of one tick
+func (c *Clock) Tick() {
+	c.ticks++
+}
+
+// Ticks returns the number of ticks since the start of the clock
+func (c *Clock) Ticks() int64 {
+	return c.ticks
+}
+
+// SetTicks sets the number of ticks of the clock (e.g. the clock of the host restored from a snapshot)
+func (c *Clock) SetTicks(ticks int64) {
+	c.ticks = ticks
+}
+
+// Now returns the simulation time since the start of the clock
+func (c *Clock) Now() time.Duration {
+	return time.Duration(c.ticks) * time.Second / time.Duration(c.tps)
+}
+
+// Since returns the simulation time elapsed since {t}
+func (c *Clock) Since(t time.Duration) time.Duration {
+	return c.Now() - t
+}
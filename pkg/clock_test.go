@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	clock := NewClock(60)
+	for range 90 {
+		clock.Tick()
+	}
+	if clock.Ticks() != 90 || clock.Now() != 1500*time.Millisecond {
+		t.Errorf("got %d ticks (%s), expected 90 ticks (1.5s)", clock.Ticks(), clock.Now())
+	}
+	if since := clock.Since(time.Second); since != 500*time.Millisecond {
+		t.Errorf("got %s since 1s, expected 500ms", since)
+	}
+
+	clock.SetTicks(120)
+	if clock.Now() != 2*time.Second {
+		t.Errorf("got %s, expected 2s", clock.Now())
+	}
+}
+
+func TestCountdownAndSetDurationOnTheClock(t *testing.T) {
+	g := &Game{
+		GameState: &GameState{ResumeGameState: &ResumeGameState{Max: 3}, StateMachine: NewStateMachine(StartGame)},
+		PlayerL:   &Player{Side: PlayerLeft},
+		PlayerR:   &Player{Side: PlayerRight},
+		Ball:      &Ball{},
+		Win:       Win{Score: 11, SetScore: 3, SetGapWScore: 2},
+		Events:    &Events{},
+		Clock:     NewClock(60),
+	}
+
+	g.StartNewSet()
+	expected := []int{3, 2, 1, 0}
+	for second, remaining := range expected {
+		if got := g.RemainingCountdown(); got != remaining {
+			t.Errorf("after %ds: got %d remaining seconds, expected %d", second, got, remaining)
+		}
+		for range 60 {
+			g.Clock.Tick()
+		}
+	}
+
+	// the set lasts 1:05 on the clock of the game whatever the wall clock
+	for range 60 * 61 {
+		g.Clock.Tick()
+	}
+	g.EndSet(*g.PlayerL)
+	if set := g.Win.Sets[0]; !set.Ended() || set.Duration() != "01:05" {
+		t.Errorf("got set %+v (%s), expected an ended set of 01:05", set, set.Duration())
+	}
+}
@@ -0,0 +1,141 @@
+package pkg
+
+// Event is something which happened in the game, the subsystems (audio, channel, logs, metrics...)
+// subscribe to them instead of being called by the simulation
+type Event interface {
+	event()
+}
+
+// PaddleHit is published when the ball hits the paddle of the {Side} player at the {Speed} X speed
+type PaddleHit struct {
+	Side  PlayerSide
+	Speed float32
+}
+
+// WallBounce is published when the ball bounces on the top or the bottom of the table
+type WallBounce struct{}
+
+// BallMoved is published a few times per second with the {X, Y} position [0..1] of the ball on the table
+// when the audio cues are enabled (0, 0 is the top left corner)
+type BallMoved struct {
+	X, Y float64
+}
+
+// PointScored is published when the {Side} player wins the point
+type PointScored struct {
+	Side PlayerSide
+}
+
+// Countdown is published on each second of the countdown before a set, {Remaining} is 0 when the set starts
+type Countdown struct {
+	Remaining int
+}
+
+// SetStarted is published when the set {Number} (from 1) starts
+type SetStarted struct {
+	Number int
+}
+
+// SetEnded is published when the {Side} player wins the set {Number}
+type SetEnded struct {
+	Number int
+	Side   PlayerSide
+}
+
+// MatchWon is published when the {Side} player wins the match
+type MatchWon struct {
+	Side PlayerSide
+}
+
+// Paused is published when the game is paused or resumed
+type Paused struct {
+	Paused bool
+}
+
+// StateChanged is published when the game goes from the {From} state to the {To} state
+type StateChanged struct {
+	From, To State
+}
+
+// PeerJoined is published when the remote player at {Addr} joins the game
+type PeerJoined struct {
+	Addr string
+}
+
+// PeerLeft is published when the remote player at {Addr} leaves the game
+type PeerLeft struct {
+	Addr string
+}
+
+func (PaddleHit) event()    {}
+func (WallBounce) event()   {}
+func (BallMoved) event()    {}
+func (PointScored) event()  {}
+func (Countdown) event()    {}
+func (SetStarted) event()   {}
+func (SetEnded) event()     {}
+func (MatchWon) event()     {}
+func (Paused) event()       {}
+func (StateChanged) event() {}
+func (PeerJoined) event()   {}
+func (PeerLeft) event()     {}
+
+// Events is the bus which dispatches the events of a game to its subscribers,
+// the events are published on the goroutine of the game loop only (the network messages are handled there too)
+type Events struct {
+	listeners []func(Event)
+	// captured holds the events published during a {Capture} instead of dispatching them
+	captured *[]Event
+}
+
+// Listen subscribes the {listener} to every event
+func (e *Events) Listen(listener func(Event)) {
+	e.listeners = append(e.listeners, listener)
+}
+
+// Subscribe subscribes the {handler} to the events of the {T} type
+func Subscribe[T Event](e *Events, handler func(T)) {
+	e.Listen(func(event Event) {
+		if t, ok := event.(T); ok {
+			handler(t)
+		}
+	})
+}
+
+// Publish sends the {event} to the subscribers in the order of their subscription
+func (e *Events) Publish(event Event) {
+	if e.captured != nil {
+		*e.captured = append(*e.captured, event)
+		return
+	}
+	for _, listener := range e.listeners {
+		listener(event)
+	}
+}
+
+// Capture runs {fn} and it returns the events published meanwhile without dispatching them to the subscribers
+// (e.g. the frames simulated by the rollback netcode which are not confirmed yet)
+func (e *Events) Capture(fn func()) []Event {
+	var captured []Event
+	e.captured = &captured
+	defer func() { e.captured = nil }()
+	fn()
+	return captured
+}
+
+// subscribers subscribe to the events of every new game
+var subscribers []func(events *Events)
+
+// ListenGames calls the {subscribe} function with the events of each new game
+func ListenGames(subscribe func(events *Events)) {
+	subscribers = append(subscribers, subscribe)
+}
+
+// newEvents builds the events of a new game
+func newEvents() *Events {
+	events := &Events{}
+	for _, subscribe := range subscribers {
+		subscribe(events)
+	}
+	return events
+}
@@ -0,0 +1,142 @@
+package pkg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	events := &Events{}
+
+	var scored []PlayerSide
+	Subscribe(events, func(e PointScored) { scored = append(scored, e.Side) })
+	var all []Event
+	events.Listen(func(e Event) { all = append(all, e) })
+
+	events.Publish(PointScored{Side: PlayerRight})
+	events.Publish(WallBounce{})
+	events.Publish(PointScored{Side: PlayerLeft})
+
+	if !slices.Equal(scored, []PlayerSide{PlayerRight, PlayerLeft}) {
+		t.Errorf("got %v", scored)
+	}
+	if len(all) != 3 || all[1] != (WallBounce{}) {
+		t.Errorf("got %v", all)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	events := &Events{}
+	var all []Event
+	events.Listen(func(e Event) { all = append(all, e) })
+
+	captured := events.Capture(func() {
+		events.Publish(WallBounce{})
+		events.Publish(PaddleHit{Side: PlayerLeft})
+	})
+	if len(all) != 0 || len(captured) != 2 || captured[1] != (PaddleHit{Side: PlayerLeft}) {
+		t.Fatalf("got %v dispatched and %v captured", all, captured)
+	}
+
+	events.Publish(WallBounce{})
+	if len(all) != 1 {
+		t.Errorf("got %v, expected the events dispatched after the capture", all)
+	}
+}
+
+func TestListenGames(t *testing.T) {
+	defer func(s []func(*Events)) { subscribers = s }(subscribers)
+
+	var nb int
+	ListenGames(func(events *Events) {
+		Subscribe(events, func(SetStarted) { nb++ })
+	})
+
+	newEvents().Publish(SetStarted{Number: 1})
+	newEvents().Publish(SetStarted{Number: 1})
+	if nb != 2 {
+		t.Errorf("got %d events, expected 2", nb)
+	}
+}
+
+func TestForfeit(t *testing.T) {
+	g := &Game{
+		GameState: &GameState{ResumeGameState: &ResumeGameState{Max: 3}, StateMachine: NewStateMachine(StartGame)},
+		PlayerL:   &Player{Side: PlayerLeft},
+		PlayerR:   &Player{Side: PlayerRight},
+		Ball:      &Ball{},
+		Win:       Win{Score: 11, SetScore: 6, SetGapWScore: 2},
+		Events:    &Events{},
+		Clock:     NewClock(60),
+	}
+	var won []PlayerSide
+	Subscribe(g.Events, func(e MatchWon) { won = append(won, e.Side) })
+
+	g.StartNewSet()
+	g.PlayerL.Score, g.PlayerR.Score = 4, 1
+	g.Forfeit(PlayerLeft)
+	if winner := g.Winner(); winner != g.PlayerR || !slices.Equal(won, []PlayerSide{PlayerRight}) {
+		t.Errorf("got winner %v and %v, expected the right player", winner, won)
+	}
+	if set := g.Win.Sets[0]; !set.Ended() || set.PlayerSideWin != PlayerRight {
+		t.Errorf("got set %+v, expected a set won by the right player", set)
+	}
+
+	g.ResetGame()
+	if g.Winner() != nil {
+		t.Error("expected no winner after a reset")
+	}
+}
+
+func TestMoved(t *testing.T) {
+	defer UseSettings(CurrentSettings())
+
+	screen := NewScreen(LocalMode)
+	g := &Game{
+		GameState: &GameState{ResumeGameState: &ResumeGameState{Max: 3}, StateMachine: NewStateMachine(StartGame)},
+		Screen:    screen,
+		Ball:      &Ball{Width: 16, Height: 16},
+		Events:    &Events{},
+		Clock:     NewClock(60),
+	}
+	var moved []BallMoved
+	Subscribe(g.Events, func(e BallMoved) { moved = append(moved, e) })
+
+	UseSettings(Settings{Volume: 1, UIScale: 1, AudioCues: true})
+	g.Moved()
+	if len(moved) != 0 {
+		t.Fatalf("got %v before the set", moved)
+	}
+
+	g.StartNewSet()
+	g.Ball.Position = Position{X: screen.XLeft - 8, Y: screen.YTop - 8}
+	for range CueInterval {
+		g.Moved()
+		g.Clock.Tick()
+	}
+	if len(moved) != 1 || moved[0] != (BallMoved{X: 0, Y: 1}) {
+		t.Errorf("got %v, expected the bottom left corner once", moved)
+	}
+
+	UseSettings(Settings{Volume: 1, UIScale: 1})
+	g.Moved()
+	if len(moved) != 1 {
+		t.Errorf("got %v without the audio cues", moved)
+	}
+}
+
+func TestStateChanged(t *testing.T) {
+	g := NewGame(LocalMode, false)
+	var changes []StateChanged
+	Subscribe(g.Events, func(e StateChanged) { changes = append(changes, e) })
+
+	for _, state := range []State{ResumeGame, PlayGame, PauseGame} {
+		if err := g.Transition(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []StateChanged{{From: StartGame, To: ResumeGame}, {From: ResumeGame, To: PlayGame}, {From: PlayGame, To: PauseGame}}
+	if !slices.Equal(changes, expected) {
+		t.Errorf("got %v, expected %v", changes, expected)
+	}
+}
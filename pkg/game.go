@@ -5,16 +5,22 @@ import (
 	"fmt"
 	"image/color"
 	"log"
+	"math"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
 	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
 	"golang.org/x/text/language"
 )
 
+// CueInterval is the number of ticks between two audio cues of the position of the ball
+const CueInterval = 15
+
+// GameTitle is the title of the game displayed on each screen
+const GameTitle = "PONG"
+
 type Game struct {
 	*GameState
 
@@ -29,6 +35,12 @@ type Game struct {
 	Ball    *Ball
 
 	Win Win
+	// Random is the pseudo-random source of the current match
+	Random *Random
+	// Clock is the simulation clock of the game, the timers are driven by it
+	Clock *Clock
+	// Events are published by the game to the subsystems (audio, channel, logs, metrics...)
+	Events *Events
 
 	Debug bool
 }
@@ -38,11 +50,14 @@ type Win struct {
 	Score        int
 	SetScore     int
 	SetGapWScore int
+	// Forfeit is the side of the player who gave up the match
+	Forfeit *PlayerSide
 }
 
+// Set represents a set of the match, its times are on the clock of the game
 type Set struct {
-	StartTime     time.Time
-	EndTime       time.Time
+	StartTime     time.Duration
+	EndTime       time.Duration
 	PlayerLScore  int
 	PlayerRScore  int
 	PlayerSideWin PlayerSide
@@ -55,8 +70,17 @@ func (s Set) HasWin(player Player) bool {
 }
 
 func (s Set) Duration() string {
-	duration := s.EndTime.Sub(s.StartTime)
-	return time.Unix(0, 0).UTC().Add(duration).Format("04:05")
+	return FormatDuration(s.EndTime - s.StartTime)
+}
+
+// Ended returns true if the set is over
+func (s Set) Ended() bool {
+	return s.EndTime > 0
+}
+
+// FormatDuration formats the {duration} as mm:ss
+func FormatDuration(duration time.Duration) string {
+	return time.Unix(0, 0).UTC().Add(duration.Round(time.Second)).Format("04:05")
 }
 
 func (s Set) Speed() float32 {
@@ -82,58 +106,61 @@ type FontText struct {
 
 type GameState struct {
 	*ResumeGameState
+	*StateMachine
 
-	CurrentState State
-	Reset        Reset
+	Reset Reset
+	// HostSide is the side of the player who hosts the game (the server)
+	HostSide PlayerSide
 }
 
 type ResumeGameState struct {
-	Max   int
-	Count int
+	Max int
+	// StartedAt is the time of the clock when the countdown started
+	StartedAt time.Duration
+	// Remaining is the last second of the countdown published
+	Remaining int
 }
 
 type Reset struct {
 	Ball Ball
 }
 
-func NewGame(mode GameMode, debug bool) *Game {
-	remoteExtendZoneW := 0
-	if mode != LocalMode {
-		remoteExtendZoneW = 350
-	}
+// NewThemedScreen builds the screen of the {mode} mode with the colours and the font
+// of the current theme and settings (e.g. the menus which only draw texts)
+func NewThemedScreen(mode GameMode) Screen {
+	settings := CurrentSettings()
+	screen := NewScreen(mode)
+	screen.AvailableColors = theme.WithPalette(settings.Palette).AvailableColors()
+	screen.Palette = settings.Palette
+	screen.Font = NewFont(ThemeFonts[theme.Font], settings.UIScale)
+	screen.Table = theme.Table
+	return screen
+}
 
-	screen := Screen{
-		Width: 1100 + remoteExtendZoneW, Height: 800, RemoteExtendZoneW: remoteExtendZoneW,
-		XLeft: 110 + float32(remoteExtendZoneW), XRight: 1090 + float32(remoteExtendZoneW), YBottom: 110, YTop: 790,
-		AvailableColors: map[string]color.Color{
-			"#atari1":   color.RGBA{246, 125, 34, 255},
-			"#atari2":   color.RGBA{249, 162, 29, 255},
-			"#atari3":   color.RGBA{253, 220, 57, 255},
-			"#bg":       color.RGBA{77, 77, 77, 255},
-			"#playerL":  color.White,
-			"#playerR":  color.White,
-			"#table-bg": color.RGBA{246, 125, 34, 255},
-			"#title":    color.RGBA{120, 226, 160, 255},
-			"white":     color.White,
-			"black":     color.White},
-		Font: NewFont()}
+func NewGame(mode GameMode, debug bool) *Game {
+	settings := CurrentSettings()
+	screen := NewThemedScreen(mode)
 
 	ball := NewBall(16, 16, Position{
 		X: float32(screen.GameZoneXCenter()) - 8,
 		Y: float32(screen.GameZoneYCenter()) - 8},
 	)
+	if mode == LocalMode && settings.SlowBall {
+		// local games only, both sides of an online game must simulate the same ball
+		ball.XSpeed, ball.YSpeed = ball.XSpeed*SlowBallRatio, ball.YSpeed*SlowBallRatio
+	}
 
-	return &Game{
+	game := &Game{
 		GameMode: mode,
 		Title: FontText{
-			Text: "PONG",
+			Text: GameTitle,
 			Font: screen.Font.H1, FontSize: screen.Font.H1Size,
-			Color: screen.AvailableColors["white"],
+			Color: screen.AvailableColors[ColorText],
 		},
 		Subtitle: FontText{
 			Text: "joakim-ribier/pong",
 			Font: screen.Font.TinyText, FontSize: screen.Font.TinyTextSize,
-			Color: screen.AvailableColors["white"],
+			Color: screen.AvailableColors[ColorText],
 		},
 		Debug:  debug,
 		Screen: screen,
@@ -145,7 +172,7 @@ func NewGame(mode GameMode, debug bool) *Game {
 				Y: float32(screen.GameZoneYCenter()) - 50,
 			}),
 			Options{
-				Color: screen.AvailableColors["#playerL"],
+				Color: screen.AvailableColors[ColorPlayerL],
 				Up:    ebiten.KeyW,
 				Down:  ebiten.KeyS}),
 		PlayerR: NewPlayer(
@@ -156,17 +183,31 @@ func NewGame(mode GameMode, debug bool) *Game {
 				Y: float32(screen.GameZoneYCenter()) - 50,
 			}),
 			Options{
-				Color: screen.AvailableColors["#playerR"],
+				Color: screen.AvailableColors[ColorPlayerR],
 				Up:    ebiten.KeyUp,
 				Down:  ebiten.KeyDown}),
 		Ball: ball,
 		GameState: &GameState{
-			CurrentState:    StartGame,
-			ResumeGameState: &ResumeGameState{Max: 3, Count: 0},
+			StateMachine:    NewStateMachine(StartGame),
+			ResumeGameState: &ResumeGameState{Max: 3},
 			Reset:           Reset{Ball: *ball},
 		},
-		Win: Win{Sets: nil, Score: 11, SetScore: 3, SetGapWScore: 2},
+		Win:    Win{Sets: nil, Score: 11, SetScore: 3, SetGapWScore: 2},
+		Random: NewRandom(NewSeed()),
+		Clock:  NewClock(ebiten.DefaultTPS),
+		Events: newEvents(),
+	}
+
+	for state := range transitions {
+		game.OnEnter(state, func(from State) { game.Events.Publish(StateChanged{From: from, To: state}) })
 	}
+	game.OnEnter(PauseGame, func(State) { game.Events.Publish(Paused{Paused: true}) })
+	game.OnExit(PauseGame, func(to State) {
+		if to == PlayGame {
+			game.Events.Publish(Paused{Paused: false})
+		}
+	})
+	return game
 }
 
 type Screen struct {
@@ -174,6 +215,9 @@ type Screen struct {
 	XLeft, XRight, YBottom, YTop     float32
 	AvailableColors                  map[string]color.Color
 	Font                             Font
+	Table                            TableStyle
+	// Palette is the colour-blind palette of the colours ("" for the colours of the theme)
+	Palette string
 }
 
 func (s Screen) GameZoneWidth() int {
@@ -198,14 +242,16 @@ type Font struct {
 	AvailableFonts                                        map[string]*text.GoTextFaceSource
 }
 
-func NewFont() Font {
-	tinyTextSize := 7
-	smallTextSize := 8
-	textSize := 12
+// NewFont builds the text faces of the game from the {face} font file, their sizes are multiplied by the {scale}
+func NewFont(face []byte, scale float64) Font {
+	scale = max(1, scale)
+	tinyTextSize := int(7 * scale)
+	smallTextSize := int(8 * scale)
+	textSize := int(12 * scale)
 	h2Size := textSize * 2
 	h1Size := textSize * 3
 
-	regularFontFace, err := text.NewGoTextFaceSource(bytes.NewReader(fonts.PressStart2P_ttf))
+	regularFontFace, err := text.NewGoTextFaceSource(bytes.NewReader(face))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -322,6 +368,22 @@ func (g Game) IsLocal() bool {
 	return !g.IsRemoteServer() && !g.IsRemoteClient()
 }
 
+// LocalSide returns the side of the player of this game (the host side for the server)
+func (g Game) LocalSide() PlayerSide {
+	if g.IsRemoteClient() {
+		return g.HostSide.Other()
+	}
+	return g.HostSide
+}
+
+// Player returns the player of the {side}
+func (g Game) Player(side PlayerSide) *Player {
+	if side == PlayerRight {
+		return g.PlayerR
+	}
+	return g.PlayerL
+}
+
 // MaxXSpeedSet returns the max x speed of the sets
 func (g Game) MaxXSpeedSet() float32 {
 	maxXSpeed := float32(0)
@@ -344,10 +406,15 @@ func (g Game) findTheOtherOne(player Player) *Player {
 func (g Game) Mark(player *Player) {
 	player.Mark()
 	g.findTheOtherOne(*player).Win = false
+	g.Events.Publish(PointScored{Side: player.Side})
 }
 
 // Winner gets the winner if there is one
 func (g Game) Winner() *Player {
+	if g.Win.Forfeit != nil {
+		return g.Player(g.Win.Forfeit.Other())
+	}
+
 	if g.PlayerL.Score >= g.Win.SetScore || g.PlayerR.Score >= g.Win.SetScore {
 		if g.PlayerL.Score-g.PlayerR.Score >= g.Win.SetGapWScore {
 			return g.PlayerL
@@ -375,42 +442,85 @@ func (g Game) Looser() *Player {
 // StartNewSet initializes a new set
 func (g *Game) StartNewSet() {
 	g.Ball.Position = g.GameState.Reset.Ball.Position
-	g.Ball.Impressions = g.GameState.Reset.Ball.Impressions
 	g.Ball.XSpeed = g.GameState.Reset.Ball.XSpeed
 	g.Ball.YSpeed = g.GameState.Reset.Ball.YSpeed
-	g.ResumeGameState.Count = 0
+	g.ResumeGameState.StartedAt = g.Clock.Now()
+	g.ResumeGameState.Remaining = 0
 
 	g.Win.Sets = append(g.Win.Sets, &Set{
-		StartTime:    time.Now(),
+		StartTime:    g.Clock.Now(),
 		XSpeed:       g.Ball.XSpeed,
 		PlayerLScore: 0,
 		PlayerRScore: 0})
+	g.Events.Publish(SetStarted{Number: len(g.Win.Sets)})
 }
 
 // EndSet updates parameters of the current set
 func (g *Game) EndSet(player Player) {
-	if len(g.Win.Sets) > 0 && g.Win.Sets[len(g.Win.Sets)-1].EndTime.IsZero() {
+	if len(g.Win.Sets) > 0 && !g.Win.Sets[len(g.Win.Sets)-1].Ended() {
 		currentSet := g.Win.Sets[len(g.Win.Sets)-1]
-		currentSet.EndTime = time.Now()
+		currentSet.EndTime = g.Clock.Now()
 		currentSet.PlayerSideWin = player.Side
 		currentSet.PlayerLScore = g.PlayerL.Score
 		currentSet.PlayerRScore = g.PlayerR.Score
+		g.Events.Publish(SetEnded{Number: len(g.Win.Sets), Side: player.Side})
+		if winner := g.Winner(); winner != nil {
+			g.Events.Publish(MatchWon{Side: winner.Side})
+		}
 	}
 }
 
+// Forfeit ends the match, the player of the {side} gives up and its opponent wins
+func (g *Game) Forfeit(side PlayerSide) {
+	g.Win.Forfeit = &side
+	g.EndSet(*g.Player(side.Other()))
+}
+
 // EndSet updates parameters of the current set
 func (g *Game) LastEndedSet() *Set {
-	winSets := slicesutil.FilterT[*Set](g.Win.Sets, func(s *Set) bool { return !s.EndTime.IsZero() })
+	winSets := slicesutil.FilterT[*Set](g.Win.Sets, func(s *Set) bool { return s.Ended() })
 	if len(winSets) == 0 {
 		return nil
 	}
 	return g.Win.Sets[len(winSets)-1]
 }
 
-// Hit computes the nb hits of the current set
-func (g *Game) Hit() {
+// Hit computes the nb hits of the current set when the ball hits the paddle of the {side} player
+func (g *Game) Hit(side PlayerSide) {
 	if set := g.CurrentSet(); set != nil {
 		set.NbHit += 1
+		g.Events.Publish(PaddleHit{Side: side, Speed: g.Ball.XSpeed})
+	}
+}
+
+// Bounce publishes the bounce of the ball on a wall during a set
+func (g *Game) Bounce() {
+	if g.CurrentSet() != nil {
+		g.Events.Publish(WallBounce{})
+	}
+}
+
+// Moved publishes the position of the ball on the table during a set each {CueInterval} ticks for the audio cues
+func (g *Game) Moved() {
+	if !CurrentSettings().AudioCues || g.CurrentSet() == nil || g.Clock.Ticks()%CueInterval != 0 {
+		return
+	}
+	s := g.Screen
+	x := (g.Ball.X + float32(g.Ball.Width)/2 - s.XLeft) / float32(s.GameZoneWidth())
+	y := (g.Ball.Y + float32(g.Ball.Height)/2 - s.YBottom) / float32(s.GameZoneHeight())
+	g.Events.Publish(BallMoved{X: math.Max(0, math.Min(1, float64(x))), Y: math.Max(0, math.Min(1, float64(y)))})
+}
+
+// RemainingCountdown returns the remaining seconds of the countdown before the set
+func (g *Game) RemainingCountdown() int {
+	return g.ResumeGameState.Max - int(g.Clock.Since(g.ResumeGameState.StartedAt)/time.Second)
+}
+
+// Countdown publishes the {remaining} seconds of the countdown before a set when they change
+func (g *Game) Countdown(remaining int) {
+	if remaining != g.ResumeGameState.Remaining {
+		g.ResumeGameState.Remaining = remaining
+		g.Events.Publish(Countdown{Remaining: remaining})
 	}
 }
 
@@ -423,18 +533,24 @@ func (g *Game) SetXSpeed(xSpeed float32) {
 
 // CurrentSet gets the current set
 func (g *Game) CurrentSet() *Set {
-	if len(g.Win.Sets) > 0 && g.Win.Sets[len(g.Win.Sets)-1].EndTime.IsZero() {
+	if len(g.Win.Sets) > 0 && !g.Win.Sets[len(g.Win.Sets)-1].Ended() {
 		return g.Win.Sets[len(g.Win.Sets)-1]
 	} else {
 		return nil
 	}
 }
 
+// NewMatch restarts the pseudo-random source of the match from the {seed}
+func (g *Game) NewMatch(seed uint64) {
+	g.Random = NewRandom(seed)
+}
+
 // ResetGame initializes a new Game
 func (g *Game) ResetGame() {
 	g.PlayerL.Score = 0
 	g.PlayerR.Score = 0
 	g.Win.Sets = nil
+	g.Win.Forfeit = nil
 }
 
 type GameMode int
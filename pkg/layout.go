@@ -0,0 +1,52 @@
+package pkg
+
+import "math"
+
+// logical size of the screen, the drawers work in these coordinates whatever the size of the window
+const (
+	// TableWidth and TableHeight are the size of the game zone (the ping-pong table)
+	TableWidth, TableHeight = 980, 680
+	// TableMarginX and TableMarginY are the space left of and above the table (title, logo and scores)
+	TableMarginX, TableMarginY = 110, 110
+	// TableBorder is the space right of and below the table
+	TableBorder = 10
+	// RemotePanelWidth is the width of the info panel displayed left of the table in the online modes
+	RemotePanelWidth = 350
+)
+
+// NewScreen builds the logical screen of the game {mode}
+func NewScreen(mode GameMode) Screen {
+	remoteExtendZoneW := 0
+	if mode != LocalMode {
+		remoteExtendZoneW = RemotePanelWidth
+	}
+
+	xLeft := float32(remoteExtendZoneW + TableMarginX)
+	return Screen{
+		Width:             remoteExtendZoneW + TableMarginX + TableWidth + TableBorder,
+		Height:            TableMarginY + TableHeight + TableBorder,
+		RemoteExtendZoneW: remoteExtendZoneW,
+		XLeft:             xLeft, XRight: xLeft + TableWidth,
+		YBottom: TableMarginY, YTop: TableMarginY + TableHeight,
+	}
+}
+
+// Viewport represents where the logical screen is drawn in the window,
+// it is scaled to fit the window and centred between two bars (letterbox)
+type Viewport struct {
+	Scale float64
+	X, Y  float64
+}
+
+// NewViewport computes the viewport of the {width}x{height} logical screen in the {outsideWidth}x{outsideHeight} window
+func NewViewport(width, height, outsideWidth, outsideHeight int) Viewport {
+	if width <= 0 || height <= 0 || outsideWidth <= 0 || outsideHeight <= 0 {
+		return Viewport{Scale: 1}
+	}
+	scale := math.Min(float64(outsideWidth)/float64(width), float64(outsideHeight)/float64(height))
+	return Viewport{
+		Scale: scale,
+		X:     math.Floor((float64(outsideWidth) - float64(width)*scale) / 2),
+		Y:     math.Floor((float64(outsideHeight) - float64(height)*scale) / 2),
+	}
+}
@@ -0,0 +1,34 @@
+package pkg
+
+import "testing"
+
+func TestNewScreen(t *testing.T) {
+	screen := NewScreen(LocalMode)
+	if screen.Width != 1100 || screen.Height != 800 || screen.XLeft != 110 || screen.XRight != 1090 || screen.YBottom != 110 || screen.YTop != 790 {
+		t.Errorf("unexpected local screen %+v", screen)
+	}
+
+	screen = NewScreen(RemoteServerMode)
+	if screen.Width != 1450 || screen.XLeft != 460 || screen.GameZoneWidth() != TableWidth || screen.GameZoneHeight() != TableHeight {
+		t.Errorf("unexpected remote screen %+v", screen)
+	}
+}
+
+func TestNewViewport(t *testing.T) {
+	tests := []struct {
+		name                        string
+		outsideWidth, outsideHeight int
+		expected                    Viewport
+	}{
+		{"same size", 1100, 800, Viewport{Scale: 1}},
+		{"twice bigger", 2200, 1600, Viewport{Scale: 2}},
+		{"wider window", 1600, 400, Viewport{Scale: 0.5, X: 525}},
+		{"taller window", 550, 1000, Viewport{Scale: 0.5, Y: 300}},
+		{"minimized window", 0, 0, Viewport{Scale: 1}},
+	}
+	for _, test := range tests {
+		if v := NewViewport(1100, 800, test.outsideWidth, test.outsideHeight); v != test.expected {
+			t.Errorf("%s: got %+v, expected %+v", test.name, v, test.expected)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package pkg
+
+import (
+	"maps"
+	"slices"
+)
+
+// Palettes are the colour-blind safe palettes (Okabe-Ito colours) which replace the colours
+// of the players and the ball of the theme, the table is black to keep them contrasted
+var Palettes = map[string]map[string]string{
+	// red-green colour blindness
+	"deuteranopia": palette("#e69f00", "#56b4e9"),
+	"protanopia":   palette("#f0e442", "#56b4e9"),
+	// blue-yellow colour blindness
+	"tritanopia": palette("#d55e00", "#009e73"),
+}
+
+// palette returns the colours of a palette with the {playerL} and {playerR} colours
+func palette(playerL, playerR string) map[string]string {
+	return map[string]string{
+		ColorPlayerL: playerL, ColorPlayerR: playerR, ColorBall: "#ffffff",
+		ColorTable: "#000000", ColorTableLine: "#ffffff",
+	}
+}
+
+// PaletteNames returns the sorted names of the palettes
+func PaletteNames() []string {
+	return slices.Sorted(maps.Keys(Palettes))
+}
+
+// WithPalette returns the theme with the colours of the {palette} ("" for the colours of the theme)
+func (t Theme) WithPalette(palette string) Theme {
+	colors := maps.Clone(t.Colors)
+	maps.Copy(colors, Palettes[palette])
+	t.Colors = colors
+	return t
+}
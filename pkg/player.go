@@ -81,3 +81,22 @@ func (p PlayerSide) String() string {
 		return "Unknown"
 	}
 }
+
+func ToPlayerSide(s string) PlayerSide {
+	switch s {
+	case "PlayerLeft":
+		return PlayerLeft
+	case "PlayerRight":
+		return PlayerRight
+	default:
+		return -1
+	}
+}
+
+// Other returns the opposite side
+func (p PlayerSide) Other() PlayerSide {
+	if p == PlayerLeft {
+		return PlayerRight
+	}
+	return PlayerLeft
+}
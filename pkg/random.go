@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"math/rand/v2"
+)
+
+// Random is the deterministic pseudo-random source of a match, the same {seed}
+// and the same inputs produce the same match on both sides, in a replay and in a test
+type Random struct {
+	seed   uint64
+	source *rand.PCG
+	*rand.Rand
+}
+
+// NewSeed returns a random seed to start a new match
+func NewSeed() uint64 {
+	return rand.Uint64()
+}
+
+// NewRandom builds the pseudo-random source of a match from the {seed}
+func NewRandom(seed uint64) *Random {
+	source := rand.NewPCG(seed, seed)
+	return &Random{seed: seed, source: source, Rand: rand.New(source)}
+}
+
+// Seed returns the seed of the match
+func (r *Random) Seed() uint64 {
+	return r.seed
+}
+
+// Range returns a pseudo-random number in [min, max)
+func (r *Random) Range(min, max float32) float32 {
+	return min + r.Float32()*(max-min)
+}
+
+// State returns the current state of the source (to save it with the state of the match)
+func (r *Random) State() []byte {
+	state, _ := r.source.MarshalBinary()
+	return state
+}
+
+// SetState restores the {state} of the source saved by {State}
+func (r *Random) SetState(state []byte) error {
+	return r.source.UnmarshalBinary(state)
+}
@@ -0,0 +1,34 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestRandomWithSameSeed(t *testing.T) {
+	r1, r2 := NewRandom(42), NewRandom(42)
+	for i := 0; i < 100; i++ {
+		if v1, v2 := r1.Range(-1, 1), r2.Range(-1, 1); v1 != v2 {
+			t.Fatalf("draw %d: got %v and %v, want the same numbers from the same seed", i, v1, v2)
+		}
+	}
+	if r1.Seed() != 42 {
+		t.Errorf("got seed %d, want 42", r1.Seed())
+	}
+}
+
+func TestRandomState(t *testing.T) {
+	random := NewRandom(7)
+	random.IntN(100)
+	state := random.State()
+	want := []int{random.IntN(100), random.IntN(100), random.IntN(100)}
+
+	// the source goes back to the saved state
+	if err := random.SetState(state); err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range want {
+		if got := random.IntN(100); got != w {
+			t.Errorf("draw %d: got %d, want %d", i, got, w)
+		}
+	}
+}
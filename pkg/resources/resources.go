@@ -1,9 +1,15 @@
 package resources
 
-import _ "embed"
+import "embed"
 
 //go:embed ball-white-16.png
 var BallWhitex16 []byte
 
 //go:embed generated-version.txt
 var Version string
+
+//go:embed themes/*.json
+var Themes embed.FS
+
+//go:embed sounds/*.wav
+var Sounds embed.FS
@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+)
+
+// UIScales are the scales of the texts available for the low-vision players
+var UIScales = []float64{1, 1.25, 1.5}
+
+// SlowBallRatio is the ratio of the speed of the ball in the slower ball mode (local games only)
+const SlowBallRatio = 0.6
+
+// Settings represents the options of the player, set from the command line and changed from the pause menu
+type Settings struct {
+	// Volume is the volume of the sound effects [0..1]
+	Volume float64
+	Mute   bool
+
+	// UIScale is the scale of the texts of the new games (see UIScales)
+	UIScale float64
+	// Palette is the colour-blind safe palette of the new games ("" for the colours of the theme)
+	Palette string
+	// HighContrastTrail draws a trail behind the ball
+	HighContrastTrail bool
+	// SlowBall slows the ball of the new local games down (see SlowBallRatio)
+	SlowBall bool
+	// AudioCues plays a cue of the position of the ball, panned by X and pitched by Y
+	AudioCues bool
+	// Effects draws the visual effects (ball trail, hit sparks, screen shake and score flash)
+	Effects bool
+}
+
+// DefaultSettings are the options of a player who did not change them
+var DefaultSettings = Settings{Volume: 0.8, UIScale: 1, Effects: true}
+
+// Validate checks the options of the player
+func (s Settings) Validate() error {
+	if s.Volume < 0 || s.Volume > 1 {
+		return fmt.Errorf("the volume must be between 0 and 1")
+	}
+	if !slices.Contains(UIScales, s.UIScale) {
+		return fmt.Errorf("the UI scale must be one of %v", UIScales)
+	}
+	if _, ok := Palettes[s.Palette]; !ok && s.Palette != "" {
+		return fmt.Errorf("unknown palette '%s' %v", s.Palette, PaletteNames())
+	}
+	return nil
+}
+
+// WithVolume returns the settings with the volume changed by {delta} and kept between 0 and 1
+func (s Settings) WithVolume(delta float64) Settings {
+	s.Volume = max(0, min(1, float64(int((s.Volume+delta)*100+0.5))/100))
+	return s
+}
+
+// WithUIScale returns the settings with the previous or next ({delta}) UI scale
+func (s Settings) WithUIScale(delta int) Settings {
+	s.UIScale = UIScales[(max(0, slices.Index(UIScales, s.UIScale))+delta+len(UIScales))%len(UIScales)]
+	return s
+}
+
+// WithPalette returns the settings with the previous or next ({delta}) palette, the colours of the theme included
+func (s Settings) WithPalette(delta int) Settings {
+	names := append([]string{""}, PaletteNames()...)
+	s.Palette = names[(max(0, slices.Index(names, s.Palette))+delta+len(names))%len(names)]
+	return s
+}
+
+// settings are changed from the game loop and read from the network goroutine (e.g. the sounds of the remote player)
+var settings = func() *atomic.Pointer[Settings] {
+	p, s := &atomic.Pointer[Settings]{}, DefaultSettings
+	p.Store(&s)
+	return p
+}()
+
+// CurrentSettings returns the options of the player
+func CurrentSettings() Settings {
+	return *settings.Load()
+}
+
+// UseSettings changes the options of the player
+func UseSettings(s Settings) {
+	settings.Store(&s)
+}
@@ -0,0 +1,80 @@
+package pkg
+
+import "testing"
+
+func TestSettingsValidate(t *testing.T) {
+	for _, volume := range []float64{-0.1, 1.1} {
+		if err := (Settings{Volume: volume}).Validate(); err == nil {
+			t.Errorf("expected an error for the volume %0.1f", volume)
+		}
+	}
+	if err := (Settings{Volume: 1, UIScale: 2}).Validate(); err == nil {
+		t.Error("expected an error for the UI scale 2")
+	}
+	if err := (Settings{Volume: 1, UIScale: 1, Palette: "unknown"}).Validate(); err == nil {
+		t.Error("expected an error for an unknown palette")
+	}
+	if err := DefaultSettings.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSettingsWithVolume(t *testing.T) {
+	tests := []struct {
+		volume, delta, expected float64
+	}{
+		{0.8, 0.1, 0.9},
+		{0.9, 0.1, 1},
+		{1, 0.1, 1},
+		{0.1, -0.1, 0},
+		{0, -0.1, 0},
+	}
+	for _, test := range tests {
+		if s := (Settings{Volume: test.volume}).WithVolume(test.delta); s.Volume != test.expected {
+			t.Errorf("volume %0.1f%+0.1f: got %v, expected %v", test.volume, test.delta, s.Volume, test.expected)
+		}
+	}
+}
+
+func TestSettingsWithUIScale(t *testing.T) {
+	s := DefaultSettings.WithUIScale(1)
+	if s.UIScale != 1.25 {
+		t.Errorf("got %v, expected 1.25", s.UIScale)
+	}
+	if s = DefaultSettings.WithUIScale(-1); s.UIScale != 1.5 {
+		t.Errorf("got %v, expected 1.5", s.UIScale)
+	}
+}
+
+func TestSettingsWithPalette(t *testing.T) {
+	s := DefaultSettings
+	for _, expected := range append(PaletteNames(), "") {
+		if s = s.WithPalette(1); s.Palette != expected {
+			t.Errorf("got palette %q, expected %q", s.Palette, expected)
+		}
+	}
+	if s = s.WithPalette(-1); s.Palette != PaletteNames()[len(PaletteNames())-1] {
+		t.Errorf("got palette %q", s.Palette)
+	}
+}
+
+func TestUseSettingsFromAnotherGoroutine(t *testing.T) {
+	defer UseSettings(CurrentSettings())
+
+	// the network goroutine reads the volume while the game loop changes it (go test -race)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			_ = CurrentSettings().Volume
+		}
+	}()
+	for i := range 100 {
+		UseSettings(DefaultSettings.WithVolume(float64(i) / 100))
+	}
+	<-done
+
+	if volume := CurrentSettings().Volume; volume != 1 {
+		t.Errorf("got the volume %0.2f, expected the last one", volume)
+	}
+}
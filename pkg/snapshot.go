@@ -0,0 +1,72 @@
+package pkg
+
+// Snapshot represents the full state of a match (score, sets, ball, paddles and random source)
+// which is sent to a reconnecting player to resume the match
+type Snapshot struct {
+	State      string   `json:"state"`
+	PlayerL    int      `json:"playerL"`
+	PlayerR    int      `json:"playerR"`
+	Sets       []Set    `json:"sets"`
+	Ball       Position `json:"ball"`
+	BallXSpeed float32  `json:"ballXSpeed"`
+	BallYSpeed float32  `json:"ballYSpeed"`
+	PaddleLY   float32  `json:"paddleLY"`
+	PaddleRY   float32  `json:"paddleRY"`
+	HostSide   string   `json:"hostSide,omitempty"`
+	Seed       uint64   `json:"seed,string"`
+	Random     []byte   `json:"random,omitempty"`
+	// Clock is the number of ticks of the clock of the host (a string to keep the 64 bits in JSON)
+	Clock int64 `json:"clock,string"`
+}
+
+// Snapshot builds the snapshot of the current match
+func (g *Game) Snapshot() Snapshot {
+	sets := make([]Set, 0, len(g.Win.Sets))
+	for _, set := range g.Win.Sets {
+		sets = append(sets, *set)
+	}
+
+	return Snapshot{
+		State:      g.CurrentState().String(),
+		PlayerL:    g.PlayerL.Score,
+		PlayerR:    g.PlayerR.Score,
+		Sets:       sets,
+		Ball:       g.Ball.Position,
+		BallXSpeed: g.Ball.XSpeed,
+		BallYSpeed: g.Ball.YSpeed,
+		PaddleLY:   g.PlayerL.Paddle.Y,
+		PaddleRY:   g.PlayerR.Paddle.Y,
+		HostSide:   g.HostSide.String(),
+		Seed:       g.Random.Seed(),
+		Random:     g.Random.State(),
+		Clock:      g.Clock.Ticks(),
+	}
+}
+
+// Restore restores the match from the {snapshot}
+func (g *Game) Restore(snapshot Snapshot) {
+	_ = g.Force(ToState(snapshot.State))
+	g.PlayerL.Score = snapshot.PlayerL
+	g.PlayerR.Score = snapshot.PlayerR
+	g.Clock.SetTicks(snapshot.Clock)
+
+	g.Win.Sets = nil
+	for _, set := range snapshot.Sets {
+		g.Win.Sets = append(g.Win.Sets, &set)
+	}
+
+	g.Ball.Position = snapshot.Ball
+	g.Ball.XSpeed = snapshot.BallXSpeed
+	g.Ball.YSpeed = snapshot.BallYSpeed
+	g.PlayerL.Paddle.Y = snapshot.PaddleLY
+	g.PlayerR.Paddle.Y = snapshot.PaddleRY
+	if side := ToPlayerSide(snapshot.HostSide); side != -1 {
+		g.HostSide = side
+	}
+	if snapshot.Random != nil {
+		random := NewRandom(snapshot.Seed)
+		if err := random.SetState(snapshot.Random); err == nil {
+			g.Random = random
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package pkg
+
+import (
+	"fmt"
+	"slices"
+)
+
+// transitions are the states allowed after each state of the game
+var transitions = map[State][]State{
+	// the host starts a match or resets the lobby (e.g. the client left)
+	StartGame: {ResumeGame, StartGame},
+	// the countdown before a set ends, the game is paused or a player lost the ball during the countdown
+	ResumeGame: {PlayGame, PauseGame, PlayerLLostBall, PlayerRLostBall, StartGame},
+	PlayGame:   {PauseGame, PlayerLLostBall, PlayerRLostBall, StartGame},
+	// the game is resumed, restarted or a player forfeits the match from the pause menu
+	PauseGame: {PlayGame, WinGame, StartGame},
+	// the next set starts or a player wins the match
+	PlayerLLostBall: {ResumeGame, WinGame, StartGame},
+	PlayerRLostBall: {ResumeGame, WinGame, StartGame},
+	WinGame:         {StartGame},
+}
+
+// TransitionError is returned by an illegal transition between two states
+type TransitionError struct {
+	From, To State
+}
+
+func (e TransitionError) Error() string {
+	return fmt.Sprintf("illegal transition %s -> %s", e.From.String(), e.To.String())
+}
+
+// StateMachine represents the lifecycle of a game, the state changes only through the declared transitions
+type StateMachine struct {
+	current State
+	onEnter map[State][]func(from State)
+	onExit  map[State][]func(to State)
+}
+
+// NewStateMachine builds a new {StateMachine} type in the {initial} state
+func NewStateMachine(initial State) *StateMachine {
+	return &StateMachine{
+		current: initial,
+		onEnter: make(map[State][]func(from State)),
+		onExit:  make(map[State][]func(to State)),
+	}
+}
+
+// CurrentState returns the current state of the game
+func (m *StateMachine) CurrentState() State {
+	return m.current
+}
+
+// CanTransition returns an error if the game can not go from the current state to the {to} state
+func (m *StateMachine) CanTransition(to State) error {
+	if !slices.Contains(transitions[m.current], to) {
+		return TransitionError{From: m.current, To: to}
+	}
+	return nil
+}
+
+// Transition goes to the {to} state, it calls the exit hooks of the current state
+// then the entry hooks of the new state
+func (m *StateMachine) Transition(to State) error {
+	if err := m.CanTransition(to); err != nil {
+		return err
+	}
+
+	from := m.current
+	for _, hook := range m.onExit[from] {
+		hook(to)
+	}
+	m.current = to
+	for _, hook := range m.onEnter[to] {
+		hook(from)
+	}
+	return nil
+}
+
+// Force sets the {state} without a transition nor hooks (e.g. the match is restored from a snapshot)
+func (m *StateMachine) Force(state State) error {
+	if _, ok := transitions[state]; !ok {
+		return fmt.Errorf("unknown state %d", state)
+	}
+	m.current = state
+	return nil
+}
+
+// OnEnter calls the {hook} each time the game enters the {state}
+func (m *StateMachine) OnEnter(state State, hook func(from State)) {
+	m.onEnter[state] = append(m.onEnter[state], hook)
+}
+
+// OnExit calls the {hook} each time the game exits the {state}
+func (m *StateMachine) OnExit(state State, hook func(to State)) {
+	m.onExit[state] = append(m.onExit[state], hook)
+}
@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var states = []State{PauseGame, PlayerLLostBall, PlayerRLostBall, PlayGame, ResumeGame, StartGame, WinGame}
+
+// legalTransitions is the matrix of the transitions allowed by the rules of a match,
+// from each state (row) to each state (column) in the order of {states}: x is legal, . is illegal
+var legalTransitions = map[State]string{
+	//               Pause LLost RLost Play Resume Start Win
+	PauseGame:       "...x.xx",
+	PlayerLLostBall: "....xxx",
+	PlayerRLostBall: "....xxx",
+	PlayGame:        "xxx..x.",
+	ResumeGame:      "xxxx.x.",
+	StartGame:       "....xx.",
+	WinGame:         ".....x.",
+}
+
+func TestTransitions(t *testing.T) {
+	for _, from := range states {
+		for i, to := range append(slices.Clone(states), State(-1)) {
+			legal := i < len(states) && legalTransitions[from][i] == 'x'
+
+			m := NewStateMachine(from)
+			err := m.Transition(to)
+
+			if legal {
+				if err != nil || m.CurrentState() != to {
+					t.Errorf("%s -> %s: got %v (state %s), expected a legal transition", from, to, err, m.CurrentState())
+				}
+				continue
+			}
+			var transitionErr TransitionError
+			if !errors.As(err, &transitionErr) || transitionErr != (TransitionError{From: from, To: to}) {
+				t.Errorf("%s -> %s: got %v, expected an illegal transition", from, to, err)
+			}
+			if m.CurrentState() != from {
+				t.Errorf("%s -> %s: the state changed to %s", from, to, m.CurrentState())
+			}
+		}
+	}
+}
+
+func TestPlayAMatch(t *testing.T) {
+	m := NewStateMachine(StartGame)
+	for _, to := range []State{ResumeGame, PlayGame, PauseGame, PlayGame, PlayerLLostBall, ResumeGame, PlayGame, PlayerRLostBall, WinGame, StartGame} {
+		if err := m.Transition(to); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHooks(t *testing.T) {
+	m := NewStateMachine(PlayGame)
+
+	var calls []string
+	m.OnExit(PlayGame, func(to State) { calls = append(calls, "exit "+PlayGame.String()+" to "+to.String()) })
+	m.OnEnter(PauseGame, func(from State) { calls = append(calls, "enter "+PauseGame.String()+" from "+from.String()) })
+	m.OnEnter(PauseGame, func(State) { calls = append(calls, "enter again") })
+
+	if err := m.Transition(StartGame); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Force(PlayGame); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Transition(WinGame); err == nil {
+		t.Fatal("expected an illegal transition")
+	}
+	if err := m.Transition(PauseGame); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"exit PlayGame to StartGame",
+		"exit PlayGame to PauseGame",
+		"enter PauseGame from PlayGame",
+		"enter again",
+	}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("got %v, expected %v", calls, expected)
+	}
+}
+
+func TestForce(t *testing.T) {
+	m := NewStateMachine(StartGame)
+	for _, state := range states {
+		if err := m.Force(state); err != nil || m.CurrentState() != state {
+			t.Errorf("force %s: got %v (state %s)", state, err, m.CurrentState())
+		}
+	}
+	if err := m.Force(ToState("Unknown")); err == nil {
+		t.Error("expected an error for an unknown state")
+	}
+}
@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/pong/pkg/resources"
+)
+
+// DefaultTheme is the theme of the game without option
+const DefaultTheme = "classic"
+
+// colour keys of a theme
+const (
+	ColorAccent1    = "#accent1"
+	ColorAccent2    = "#accent2"
+	ColorAccent3    = "#accent3"
+	ColorBackground = "#bg"
+	ColorBall       = "#ball"
+	ColorLog        = "#log"
+	ColorPanel      = "#panel"
+	ColorPlayerL    = "#playerL"
+	ColorPlayerR    = "#playerR"
+	ColorTable      = "#table-bg"
+	ColorTableLine  = "#table-line"
+	ColorText       = "#text"
+	ColorTitle      = "#title"
+)
+
+// ThemeColors are the colour keys defined by every theme
+var ThemeColors = []string{
+	ColorAccent1, ColorAccent2, ColorAccent3, ColorBackground, ColorBall, ColorLog, ColorPanel,
+	ColorPlayerL, ColorPlayerR, ColorTable, ColorTableLine, ColorText, ColorTitle,
+}
+
+// BackgroundColors are the colour keys which can not be picked for a paddle
+var BackgroundColors = []string{ColorBackground, ColorPanel, ColorTable}
+
+// ThemeFonts are the font faces available for a theme
+var ThemeFonts = map[string][]byte{
+	"press-start-2p": fonts.PressStart2P_ttf,
+	"mplus-1p":       fonts.MPlus1pRegular_ttf,
+}
+
+// Theme represents the colours, the font face and the table decoration of the game
+type Theme struct {
+	Name   string            `json:"name"`
+	Font   string            `json:"font"`
+	Colors map[string]string `json:"colors"`
+	Table  TableStyle        `json:"table"`
+}
+
+// TableStyle represents the decoration options of the ping-pong table
+type TableStyle struct {
+	Logo         bool `json:"logo"`
+	Border       bool `json:"border"`
+	CenterLine   bool `json:"centerLine"`
+	CenterCircle bool `json:"centerCircle"`
+}
+
+// Themes returns the names of the themes embedded in the game
+func Themes() []string {
+	entries, _ := fs.ReadDir(resources.Themes, "themes")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name()[:len(entry.Name())-len(".json")])
+	}
+	return names
+}
+
+// LoadTheme loads the embedded theme {name} or the theme file at the {name} path
+func LoadTheme(name string) (Theme, error) {
+	data, err := resources.Themes.ReadFile("themes/" + name + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return Theme{}, fmt.Errorf("theme '%s' not found (%v): %w", name, Themes(), err)
+	}
+
+	theme, err := jsonsutil.Unmarshal[Theme](data)
+	if err != nil {
+		return Theme{}, fmt.Errorf("invalid theme '%s': %w", name, err)
+	}
+	return theme, theme.Validate()
+}
+
+// Validate checks that the theme defines every colour key and a known font face
+func (t Theme) Validate() error {
+	if _, ok := ThemeFonts[t.Font]; !ok {
+		return fmt.Errorf("theme '%s': unknown font '%s'", t.Name, t.Font)
+	}
+	for _, key := range ThemeColors {
+		if _, err := ParseColor(t.Colors[key]); err != nil {
+			return fmt.Errorf("theme '%s': colour %s: %w", t.Name, key, err)
+		}
+	}
+	return nil
+}
+
+// AvailableColors returns the colours of the theme by key
+func (t Theme) AvailableColors() map[string]color.Color {
+	colors := make(map[string]color.Color, len(t.Colors))
+	for key, value := range t.Colors {
+		if c, err := ParseColor(value); err == nil {
+			colors[key] = c
+		}
+	}
+	return colors
+}
+
+// ParseColor parses the {s} #rrggbb colour
+func ParseColor(s string) (color.Color, error) {
+	var r, g, b uint8
+	if n, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil || n != 3 || len(s) != len("#rrggbb") {
+		return nil, fmt.Errorf("invalid colour '%s' (#rrggbb)", s)
+	}
+	return color.RGBA{r, g, b, 255}, nil
+}
+
+// theme is the theme of the new games
+var theme = func() Theme {
+	t, err := LoadTheme(DefaultTheme)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return t
+}()
+
+// UseTheme applies the {t} theme to the new games
+func UseTheme(t Theme) {
+	theme = t
+}
+
+// CurrentTheme returns the theme of the new games
+func CurrentTheme() Theme {
+	return theme
+}
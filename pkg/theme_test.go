@@ -0,0 +1,57 @@
+package pkg
+
+import "testing"
+
+func TestLoadThemes(t *testing.T) {
+	for _, name := range Themes() {
+		theme, err := LoadTheme(name)
+		if err != nil {
+			t.Fatalf("theme %s: %v", name, err)
+		}
+		if theme.Name != name {
+			t.Errorf("theme %s: got name %s", name, theme.Name)
+		}
+	}
+}
+
+func TestLoadUnknownTheme(t *testing.T) {
+	if _, err := LoadTheme("unknown"); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	for _, s := range []string{"", "#fff", "#gg0000", "ff0000", "#ff00001"} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+	if _, err := ParseColor("#ff8000"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestThemeWithPalette(t *testing.T) {
+	theme, err := LoadTheme(DefaultTheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range PaletteNames() {
+		palette := theme.WithPalette(name)
+		if err := palette.Validate(); err != nil {
+			t.Errorf("palette %s: %v", name, err)
+		}
+		if palette.Colors[ColorPlayerL] == palette.Colors[ColorPlayerR] {
+			t.Errorf("palette %s: the players have the same colour", name)
+		}
+		if palette.Colors[ColorTitle] != theme.Colors[ColorTitle] {
+			t.Errorf("palette %s: the title colour of the theme is replaced", name)
+		}
+	}
+	if theme.WithPalette("").Colors[ColorPlayerL] != theme.Colors[ColorPlayerL] {
+		t.Error("expected the colours of the theme without palette")
+	}
+	if theme.WithPalette(PaletteNames()[0]); theme.Colors[ColorPlayerL] != "#ffffff" {
+		t.Error("expected the colours of the theme to be unchanged")
+	}
+}
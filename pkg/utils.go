@@ -2,11 +2,10 @@ package pkg
 
 import (
 	"bytes"
+	"fmt"
 	"image"
 	"log"
 	"net"
-	"strconv"
-	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -20,18 +19,16 @@ func GetImg(data []byte) *ebiten.Image {
 	return ebiten.NewImageFromImage(img)
 }
 
-func ToUDPAddrUnsafe(addr string) *net.UDPAddr {
-	addrT := strings.Split(addr, ":")
-
-	ip := addrT[0]
-	port := 0
-	if p, err := strconv.ParseInt(addrT[1], 10, 64); err == nil {
-		port = int(p)
+// ResolveUDPAddr resolves the {addr} UDP address [host:port] where the host
+// can be a DNS name, an IPv4, an [IPv6] or empty (:port)
+func ResolveUDPAddr(addr string) (*net.UDPAddr, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, fmt.Errorf("invalid address '%s' (host:port): %w", addr, err)
 	}
 
-	return &net.UDPAddr{
-		IP:   net.ParseIP(ip),
-		Port: port,
-		Zone: "",
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("fail to resolve address '%s': %w", addr, err)
 	}
+	return udpAddr, nil
 }
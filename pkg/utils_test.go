@@ -0,0 +1,35 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestResolveUDPAddr(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:3000", "localhost:3000", "[::1]:3000"} {
+		udpAddr, err := ResolveUDPAddr(addr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", addr, err)
+		}
+		if !udpAddr.IP.IsLoopback() || udpAddr.Port != 3000 {
+			t.Errorf("%s: got %s, want a loopback address on port 3000", addr, udpAddr.String())
+		}
+	}
+}
+
+func TestResolveUDPAddrWithPortOnly(t *testing.T) {
+	udpAddr, err := ResolveUDPAddr(":3000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if udpAddr.IP != nil || udpAddr.Port != 3000 {
+		t.Errorf("got %s, want :3000", udpAddr.String())
+	}
+}
+
+func TestResolveUDPAddrWithInvalidAddress(t *testing.T) {
+	for _, addr := range []string{"", "127.0.0.1", "127.0.0.1:abc", "127.0.0.1:99999", "::1:3000"} {
+		if _, err := ResolveUDPAddr(addr); err == nil {
+			t.Errorf("%s: expected an error", addr)
+		}
+	}
+}